@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/sywesk/audiomix/pkg/audiograph/components"
+	"github.com/sywesk/audiomix/pkg/audiograph/ddl"
+)
+
+func validateCommand(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+
+	positional, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	_, err = ddl.LoadFile(positional[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: ok\n", positional[0])
+	return nil
+}
+
+func listComponentsCommand(args []string) error {
+	flags := flag.NewFlagSet("list-components", flag.ContinueOnError)
+
+	_, err := parseArgs(flags, args, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range components.List() {
+		fmt.Println(name)
+	}
+
+	return nil
+}
+
+func describeCommand(args []string) error {
+	flags := flag.NewFlagSet("describe", flag.ContinueOnError)
+
+	positional, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	component, err := components.Instanciate(positional[0])
+	if err != nil {
+		return fmt.Errorf("component '%s': %w", positional[0], err)
+	}
+
+	description := component.GetDescription()
+
+	fmt.Printf("%s\n", positional[0])
+
+	fmt.Printf("\nparameters:\n")
+	for _, param := range description.Parameters {
+		fmt.Printf("  %-12s %-8s %s\n", param.Name, param.Value.Type, param.Description)
+	}
+
+	fmt.Printf("\ninputs:\n")
+	for _, input := range description.Inputs {
+		fmt.Printf("  %-12s %-8s %s\n", input.Name, input.Value.Type, input.Description)
+	}
+
+	fmt.Printf("\noutputs:\n")
+	for _, output := range description.Outputs {
+		fmt.Printf("  %-12s %-8s %s\n", output.Name, output.Value.Type, output.Description)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/hajimehoshi/oto/v2"
+	"github.com/sywesk/audiomix/pkg/audiograph"
+	"github.com/sywesk/audiomix/pkg/audiograph/ddl"
+)
+
+const (
+	defaultSamplingFrequency = 48000
+)
+
+func playCommand(args []string) error {
+	flags := flag.NewFlagSet("play", flag.ContinueOnError)
+	duration := flags.Duration("duration", 0, "how long to play, forever when 0")
+	deviceRate := flags.Uint("device-rate", 0, "sampling frequency of the audio device, defaults to the one of the graph")
+
+	positional, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+
+	graph, err := ddl.LoadFile(positional[0])
+	if err != nil {
+		return err
+	}
+
+	// The graph is rendered at the device rate, since oto does not resample
+	samplingFrequency := graph.SamplingFrequency()
+	if *deviceRate != 0 {
+		samplingFrequency = uint32(*deviceRate)
+	} else if samplingFrequency == 0 {
+		samplingFrequency = defaultSamplingFrequency
+	}
+	graph.SetSamplingFrequency(samplingFrequency)
+
+	// Devices are fed float samples whatever the output format of the graph, oto
+	// not supporting 24 and 32 bits integers
+	err = graph.SetOutputFormat(audiograph.Float32OutputFormat)
+	if err != nil {
+		return fmt.Errorf("failed to set output format: %w", err)
+	}
+
+	// oto only plays mono and stereo: graphs with more channels are downmixed
+	var source io.Reader = graph
+	channels := graph.Channels()
+	if channels > 2 {
+		source = &stereoDownmixer{graph: graph, channels: channels}
+		channels = 2
+	}
+
+	otoCtx, ctxReady, err := oto.NewContext(int(samplingFrequency), channels, oto.FormatFloat32LE)
+	if err != nil {
+		return fmt.Errorf("failed to init oto: %w", err)
+	}
+
+	<-ctxReady
+
+	player := otoCtx.NewPlayer(source)
+	defer player.Close()
+	player.Play()
+
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt)
+	defer signal.Stop(interrupted)
+
+	var timeout <-chan time.Time
+	if *duration > 0 {
+		timeout = time.After(*duration)
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timeout:
+			return nil
+		case <-interrupted:
+			return nil
+		case <-ticker.C:
+			if player.Err() != nil {
+				return fmt.Errorf("failed to play: %w", player.Err())
+			}
+		}
+	}
+}
+
+// stereoDownmixer reads float samples from a graph with more than two channels
+// and turns them into stereo frames. Odd channels are averaged into the left
+// side, and even channels into the right side.
+type stereoDownmixer struct {
+	graph    *audiograph.AudioGraph
+	channels int
+	buffer   []byte
+}
+
+func (d *stereoDownmixer) Read(p []byte) (int, error) {
+	sampleSize := audiograph.Float32OutputFormat.BytesPerSample()
+
+	frames := len(p) / (2 * sampleSize)
+	size := frames * d.channels * sampleSize
+	if cap(d.buffer) < size {
+		d.buffer = make([]byte, size)
+	}
+
+	n, err := d.graph.Read(d.buffer[:size])
+	frames = n / (d.channels * sampleSize)
+
+	for frame := 0; frame < frames; frame++ {
+		var sides [2]float32
+		for channel := 0; channel < d.channels; channel++ {
+			offset := (frame*d.channels + channel) * sampleSize
+			sides[channel%2] += math.Float32frombits(binary.LittleEndian.Uint32(d.buffer[offset:]))
+		}
+
+		for side, sum := range sides {
+			// The left side gets the extra channel when their count is odd
+			count := (d.channels + 1 - side) / 2
+			offset := (frame*2 + side) * sampleSize
+			binary.LittleEndian.PutUint32(p[offset:], math.Float32bits(sum/float32(count)))
+		}
+	}
+
+	return frames * 2 * sampleSize, err
+}
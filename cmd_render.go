@@ -0,0 +1,120 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sywesk/audiomix/pkg/audiograph/ddl"
+	"github.com/sywesk/audiomix/pkg/render"
+	"github.com/sywesk/audiomix/pkg/wav"
+)
+
+func renderCommand(args []string) error {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	split := flags.Bool("split", false, "writes each channel to its own file, named after the output path")
+	stemsSpec := flags.String("stems", "", "groups of channels written to their own file, named after the output path. e.g. 1+2,3+4")
+	formatName := flags.String("format", "", "sample format of the WAV file: pcm16, pcm24, pcm32 or float32. defaults to the output format of the graph")
+
+	positional, err := parseArgs(flags, args, 3)
+	if err != nil {
+		return err
+	}
+
+	duration, err := parseDuration(positional[1])
+	if err != nil {
+		return err
+	}
+
+	graph, err := ddl.LoadFile(positional[0])
+	if err != nil {
+		return err
+	}
+
+	format := render.DefaultFormat(graph)
+	if *formatName != "" {
+		format, err = wav.ParseFormat(*formatName)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case *split && *stemsSpec != "":
+		return fmt.Errorf("--split and --stems cannot be used together: %w", errUsage)
+	case *split:
+		channels := make([][]int, graph.Channels())
+		for channel := range channels {
+			channels[channel] = []int{channel}
+		}
+
+		return render.RenderStemFiles(graph, duration, format, stemsFor(positional[2], "ch", channels))
+	case *stemsSpec != "":
+		channels, err := parseStems(*stemsSpec)
+		if err != nil {
+			return err
+		}
+
+		return render.RenderStemFiles(graph, duration, format, stemsFor(positional[2], "stem", channels))
+	}
+
+	return render.RenderFile(graph, duration, format, positional[2])
+}
+
+// parseStems parses groups of channels numbered from 1, such as "1+2,3+4",
+// into groups of channels numbered from 0.
+func parseStems(spec string) ([][]int, error) {
+	var stems [][]int
+
+	for _, group := range strings.Split(spec, ",") {
+		var channels []int
+
+		for _, channel := range strings.Split(group, "+") {
+			number, err := strconv.Atoi(strings.TrimSpace(channel))
+			if err != nil || number < 1 {
+				return nil, fmt.Errorf("invalid channel '%s' in stems '%s'", channel, spec)
+			}
+
+			channels = append(channels, number-1)
+		}
+
+		stems = append(stems, channels)
+	}
+
+	return stems, nil
+}
+
+// stemsFor names the file of each group of channels after the output path:
+// out.wav becomes out_ch1.wav, out_ch2.wav, ...
+func stemsFor(outputPath string, prefix string, channels [][]int) []render.Stem {
+	extension := filepath.Ext(outputPath)
+	base := strings.TrimSuffix(outputPath, extension)
+
+	stems := make([]render.Stem, len(channels))
+	for i := range channels {
+		stems[i] = render.Stem{
+			Channels: channels[i],
+			Path:     fmt.Sprintf("%s_%s%d%s", base, prefix, i+1, extension),
+		}
+	}
+
+	return stems
+}
+
+// parseDuration accepts either a Go duration ("1m30s") or a number of seconds.
+func parseDuration(value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err == nil {
+		return duration, nil
+	}
+
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration '%s'", value)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -1,33 +1,113 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"github.com/hajimehoshi/oto/v2"
-	"github.com/sywesk/audiomix/pkg/audiograph/ddl"
-	"time"
+	"os"
+	"sort"
 )
 
-const (
-	SAMPLE_RATE = 48000
+var (
+	errUsage = errors.New("usage error")
+)
+
+type command struct {
+	usage       string
+	description string
+	run         func(args []string) error
+}
+
+var (
+	commands = map[string]command{
+		"play": {
+			usage:       "play <graph path> [--duration <duration>] [--device-rate <hz>]",
+			description: "plays a graph on the default audio device",
+			run:         playCommand,
+		},
+		"render": {
+			usage:       "render <graph path> <duration> <output path> [--format pcm16|pcm24|pcm32|float32] [--split | --stems 1+2,3+4]",
+			description: "renders a graph to a WAV file, as fast as possible",
+			run:         renderCommand,
+		},
+		"validate": {
+			usage:       "validate <graph path>",
+			description: "loads a graph and reports any error",
+			run:         validateCommand,
+		},
+		"list-components": {
+			usage:       "list-components",
+			description: "lists the components usable in graphs",
+			run:         listComponentsCommand,
+		},
+		"describe": {
+			usage:       "describe <component>",
+			description: "describes the parameters, inputs and outputs of a component",
+			run:         describeCommand,
+		},
+	}
 )
 
 func main() {
-	otoCtx, ctxReady, err := oto.NewContext(SAMPLE_RATE, 2, oto.FormatSignedInt16LE)
-	if err != nil {
-		panic("failed to init oto: " + err.Error())
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(2)
 	}
 
-	<-ctxReady
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command '%s'\n\n", os.Args[1])
+		printUsage()
+		os.Exit(2)
+	}
 
-	graph, err := ddl.LoadFile("./examples/sin_sin.audiograph")
-	if err != nil {
-		fmt.Printf("error: %v\n", err)
+	err := cmd.run(os.Args[2:])
+	if errors.Is(err, errUsage) {
+		fmt.Fprintf(os.Stderr, "usage: audiomix %s\n", cmd.usage)
+		os.Exit(2)
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
 	}
+}
 
-	player := otoCtx.NewPlayer(graph)
-	player.Play()
+func printUsage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
-	time.Sleep(5 * time.Second)
+	fmt.Fprintf(os.Stderr, "usage: audiomix <command> [arguments]\n\ncommands:\n")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %s\n      %s\n", commands[name].usage, commands[name].description)
+	}
+}
+
+// parseArgs parses flags wherever they are placed among the positional
+// arguments, which are returned. Exactly expectedArgs positional arguments
+// must be given.
+func parseArgs(flags *flag.FlagSet, args []string, expectedArgs int) ([]string, error) {
+	flags.SetOutput(os.Stderr)
+
+	var positional []string
+	for {
+		err := flags.Parse(args)
+		if err != nil {
+			return nil, errUsage
+		}
+
+		if flags.NArg() == 0 {
+			break
+		}
+
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+
+	if len(positional) != expectedArgs {
+		return nil, errUsage
+	}
 
-	player.Close()
+	return positional, nil
 }
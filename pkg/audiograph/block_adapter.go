@@ -0,0 +1,71 @@
+package audiograph
+
+type blockAdapter struct {
+	component Component
+}
+
+// NewBlockAdapter turns a per-sample Component into a BlockComponent. For each
+// frame of the block, the adapter loads the input buffers into the description
+// of the component, executes it, and stores its outputs back into the buffers.
+func NewBlockAdapter(component Component) BlockComponent {
+	return &blockAdapter{
+		component: component,
+	}
+}
+
+func (b *blockAdapter) GetDescription() *ComponentDescription {
+	return b.component.GetDescription()
+}
+
+func (b *blockAdapter) ProcessBlock(ctx BlockContext, frames int) error {
+	description := b.component.GetDescription()
+
+	for i := 0; i < frames; i++ {
+		for portID := range description.Inputs {
+			ctx.Inputs[portID][i].CopyTo(&description.Inputs[portID].Value)
+		}
+
+		err := b.component.Execute(ctx.ExecutionContext)
+		if err != nil {
+			return err
+		}
+
+		for portID := range description.Outputs {
+			ctx.Outputs[portID][i] = description.Outputs[portID].Value
+		}
+	}
+
+	return nil
+}
+
+// OnParameterChange forwards the notification to the adapted component when it
+// is a ParameterObserver.
+func (b *blockAdapter) OnParameterChange(name string) error {
+	observer, ok := b.component.(ParameterObserver)
+	if !ok {
+		return nil
+	}
+
+	return observer.OnParameterChange(name)
+}
+
+// Seed forwards the seed to the adapted component when it is Seedable.
+func (b *blockAdapter) Seed(seed int64) {
+	seedable, ok := b.component.(Seedable)
+	if !ok {
+		return
+	}
+
+	seedable.Seed(seed)
+}
+
+// Prepare forwards the sampling frequency to the adapted component when it is
+// Preparable.
+func (b *blockAdapter) Prepare(samplingFrequency uint32) {
+	preparable, ok := b.component.(Preparable)
+	if !ok {
+		return
+	}
+
+	preparable.Prepare(samplingFrequency)
+}
@@ -1,6 +1,10 @@
 package audiograph
 
-type SampleType int16
+import "fmt"
+
+// SampleType holds the value of a single audio channel, nominally between -1.0
+// and 1.0. It is only converted to the output format of the graph when read.
+type SampleType float64
 
 type Sample struct {
 	Left  SampleType
@@ -10,13 +14,33 @@ type Sample struct {
 type ValueType int
 
 const (
-	IntegerValueType ValueType = 1
-	FloatValueType   ValueType = 2
-	SampleValueType  ValueType = 3
-	BoolValueType    ValueType = 4
-	StringValueType  ValueType = 5
+	IntegerValueType   ValueType = 1
+	FloatValueType     ValueType = 2
+	SampleValueType    ValueType = 3
+	BoolValueType      ValueType = 4
+	StringValueType    ValueType = 5
+	FloatListValueType ValueType = 6
 )
 
+func (t ValueType) String() string {
+	switch t {
+	case IntegerValueType:
+		return "integer"
+	case FloatValueType:
+		return "float"
+	case SampleValueType:
+		return "sample"
+	case BoolValueType:
+		return "bool"
+	case StringValueType:
+		return "string"
+	case FloatListValueType:
+		return "float list"
+	}
+
+	return fmt.Sprintf("unknown(%d)", int(t))
+}
+
 type Value struct {
 	Type    ValueType
 	Integer int64
@@ -24,6 +48,7 @@ type Value struct {
 	Sample  Sample
 	Bool    bool
 	String  string
+	Floats  []float64
 }
 
 func (v Value) CopyTo(dest *Value) {
@@ -32,6 +57,8 @@ func (v Value) CopyTo(dest *Value) {
 	dest.Float = v.Float
 	dest.Sample = v.Sample
 	dest.Bool = v.Bool
+	dest.String = v.String
+	dest.Floats = v.Floats
 }
 
 type ComponentInput struct {
@@ -66,3 +93,50 @@ type Component interface {
 	GetDescription() *ComponentDescription
 	Execute(ExecutionContext) error
 }
+
+// ParameterObserver is implemented by components needing to act as soon as one
+// of their parameters is set, e.g. to load a file. The new value is already
+// stored in the description when OnParameterChange is called. An error makes
+// AudioGraph.SetParameter fail and restores the previous value, so observers
+// must leave their state untouched when they fail. Observers may also change
+// the inputs of their description, the graph then updates the ports of the
+// component. A value removing a connected input is rejected with
+// ErrConnectedInputRemoved, and OnParameterChange is called again with the
+// previous value.
+type ParameterObserver interface {
+	OnParameterChange(name string) error
+}
+
+// Seedable is implemented by components relying on random numbers. The graph
+// seeds every such component from its own seed, so that renders of a graph are
+// reproducible.
+type Seedable interface {
+	Seed(seed int64)
+}
+
+// Preparable is implemented by components doing heavy work that depends on the
+// sampling frequency, such as resampling a file. The graph calls Prepare
+// outside of Read, so that this work does not run on the audio path: when the
+// component is added, after each of its parameters is set, and when the
+// sampling frequency changes.
+type Preparable interface {
+	Prepare(samplingFrequency uint32)
+}
+
+// BlockContext is handed to BlockComponent.ProcessBlock. Inputs and Outputs
+// hold one buffer per port, in the order of the component description. Each
+// buffer holds at least as many values as there are frames in the block.
+type BlockContext struct {
+	ExecutionContext
+
+	Inputs  [][]Value
+	Outputs [][]Value
+}
+
+// BlockComponent is a component processing a whole block of frames per call,
+// reading its inputs from and writing its outputs to per-port buffers instead
+// of its description values.
+type BlockComponent interface {
+	GetDescription() *ComponentDescription
+	ProcessBlock(ctx BlockContext, frames int) error
+}
@@ -0,0 +1,273 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+var (
+	ErrUnknownEnvelopeCurve     = fmt.Errorf("unknown envelope curve")
+	ErrUnknownRetriggerBehavior = fmt.Errorf("unknown retrigger behavior")
+)
+
+type envelopeStage int
+
+const (
+	idleStage envelopeStage = iota
+	attackStage
+	decayStage
+	sustainStage
+	releaseStage
+)
+
+const (
+	// Exponential segments aim beyond their target so that they reach it in
+	// the requested time. The smaller the overshoot, the more curved the
+	// segment: the attack stays close to a linear ramp, as analog envelopes do.
+	attackOvershoot = 0.3
+	decayOvershoot  = 0.0001
+)
+
+// exponentialRate caches the coefficient of an exponential segment, which is
+// only recomputed when its duration changes.
+type exponentialRate struct {
+	samples float64
+	coef    float64
+}
+
+func (e *exponentialRate) get(samples float64, overshoot float64) float64 {
+	if samples != e.samples {
+		e.samples = samples
+		e.coef = math.Exp(-math.Log((1+overshoot)/overshoot) / samples)
+	}
+
+	return e.coef
+}
+
+// ADSR generates an envelope going from 0 to 1 when its gate opens (attack),
+// down to the sustain level (decay), and back to 0 when its gate closes
+// (release).
+type ADSR struct {
+	description audiograph.ComponentDescription
+
+	exponential bool
+	retrigger   string
+
+	stage    envelopeStage
+	level    float64
+	lastGate bool
+
+	// from is the level when the current stage started, for linear segments
+	from float64
+
+	attackRate  exponentialRate
+	decayRate   exponentialRate
+	releaseRate exponentialRate
+}
+
+func NewADSR() *ADSR {
+	return &ADSR{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				{
+					Name:        "curve",
+					Description: "shape of the segments: linear or exponential (default)",
+					Value: audiograph.Value{
+						Type:   audiograph.StringValueType,
+						String: "exponential",
+					},
+				},
+				{
+					Name: "retrigger",
+					Description: "behavior when the gate opens before the end of the release: soft (default) attacks " +
+						"from the current level, hard restarts from 0, legato skips the attack and goes to the sustain level",
+					Value: audiograph.Value{
+						Type:   audiograph.StringValueType,
+						String: "soft",
+					},
+				},
+			},
+			Inputs: []audiograph.ComponentInput{
+				{
+					Name:        "gate",
+					Description: "starts the envelope when going from false to true, releases it when going back to false",
+					Value: audiograph.Value{
+						Type: audiograph.BoolValueType,
+					},
+				},
+				{
+					Name:        "attack",
+					Description: "time to go from 0 to 1, in seconds",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 0.01,
+					},
+				},
+				{
+					Name:        "decay",
+					Description: "time to go from 1 to the sustain level, in seconds",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 0.1,
+					},
+				},
+				{
+					Name:        "sustain",
+					Description: "level held while the gate is open. from 0 to 1",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 1.0,
+					},
+				},
+				{
+					Name:        "release",
+					Description: "time to go back to 0 once the gate is closed, in seconds",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 0.1,
+					},
+				},
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "env",
+					Description: "envelope, from 0 to 1",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+		exponential: true,
+		retrigger:   "soft",
+	}
+}
+
+func (a *ADSR) GetDescription() *audiograph.ComponentDescription {
+	return &a.description
+}
+
+func (a *ADSR) OnParameterChange(name string) error {
+	switch name {
+	case "curve":
+		curve := a.description.Parameters[0].Value.String
+
+		switch strings.ToLower(curve) {
+		case "linear":
+			a.exponential = false
+		case "exponential":
+			a.exponential = true
+		default:
+			return fmt.Errorf("curve '%s': %w", curve, ErrUnknownEnvelopeCurve)
+		}
+	case "retrigger":
+		retrigger := strings.ToLower(a.description.Parameters[1].Value.String)
+
+		switch retrigger {
+		case "soft", "hard", "legato":
+			a.retrigger = retrigger
+		default:
+			return fmt.Errorf("retrigger '%s': %w", retrigger, ErrUnknownRetriggerBehavior)
+		}
+	}
+
+	return nil
+}
+
+func (a *ADSR) Execute(ctx audiograph.ExecutionContext) error {
+	gate := a.description.Inputs[0].Value.Bool
+	sustain := clamp(a.description.Inputs[3].Value.Float, 0, 1)
+
+	if gate && !a.lastGate {
+		a.open()
+	} else if !gate && a.lastGate {
+		a.enter(releaseStage)
+	}
+	a.lastGate = gate
+
+	sr := float64(ctx.SamplingFrequency)
+
+	switch a.stage {
+	case attackStage:
+		samples := a.description.Inputs[1].Value.Float * sr
+		if a.segment(samples, 1, attackOvershoot, &a.attackRate) {
+			a.enter(decayStage)
+		}
+	case decayStage:
+		samples := a.description.Inputs[2].Value.Float * sr
+		if a.segment(samples, sustain, decayOvershoot, &a.decayRate) {
+			a.enter(sustainStage)
+		}
+	case sustainStage:
+		// Follows the sustain input, which may be modulated
+		a.level = sustain
+	case releaseStage:
+		samples := a.description.Inputs[4].Value.Float * sr
+		if a.segment(samples, 0, decayOvershoot, &a.releaseRate) {
+			a.enter(idleStage)
+		}
+	}
+
+	a.description.Outputs[0].Value.Float = a.level
+
+	return nil
+}
+
+// open starts the envelope, according to the retrigger behavior when it is
+// still running.
+func (a *ADSR) open() {
+	switch {
+	case a.stage == idleStage || a.retrigger == "soft":
+		a.enter(attackStage)
+	case a.retrigger == "hard":
+		a.level = 0
+		a.enter(attackStage)
+	case a.retrigger == "legato":
+		a.enter(decayStage)
+	}
+}
+
+func (a *ADSR) enter(stage envelopeStage) {
+	a.stage = stage
+	a.from = a.level
+}
+
+// segment moves the level towards target, the whole segment lasting the given
+// number of samples. It returns true once the target is reached.
+func (a *ADSR) segment(samples, target, overshoot float64, rate *exponentialRate) bool {
+	rising := target > a.level
+
+	if samples < 1 || a.level == target {
+		a.level = target
+		return true
+	}
+
+	if a.exponential {
+		coef := rate.get(samples, overshoot)
+		aim := target - overshoot
+		if rising {
+			aim = target + overshoot
+		}
+
+		a.level = aim*(1-coef) + a.level*coef
+	} else {
+		// The target may have moved since the start of the segment, as the
+		// sustain level can be modulated
+		step := math.Max(math.Abs(target-a.from), math.Abs(target-a.level)) / samples
+		if !rising {
+			step = -step
+		}
+
+		a.level += step
+	}
+
+	if (rising && a.level >= target) || (!rising && a.level <= target) {
+		a.level = target
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,149 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// binaryOperator is a component computing its output from two float inputs, a
+// and b. The arithmetic components embed it.
+type binaryOperator struct {
+	description audiograph.ComponentDescription
+	operation   func(a, b float64) float64
+}
+
+func newBinaryOperator(result string, b float64, operation func(a, b float64) float64) binaryOperator {
+	return binaryOperator{
+		description: audiograph.ComponentDescription{
+			Inputs: []audiograph.ComponentInput{
+				floatInput("a", "first operand", 0),
+				floatInput("b", "second operand", b),
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "out",
+					Description: result,
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+		operation: operation,
+	}
+}
+
+func (o *binaryOperator) GetDescription() *audiograph.ComponentDescription {
+	return &o.description
+}
+
+func (o *binaryOperator) Execute(ctx audiograph.ExecutionContext) error {
+	a := o.description.Inputs[0].Value.Float
+	b := o.description.Inputs[1].Value.Float
+
+	o.description.Outputs[0].Value.Float = o.operation(a, b)
+
+	return nil
+}
+
+// unaryOperator is a component computing its output from a single float input.
+type unaryOperator struct {
+	description audiograph.ComponentDescription
+	operation   func(in float64) float64
+}
+
+func newUnaryOperator(result string, operation func(in float64) float64) unaryOperator {
+	return unaryOperator{
+		description: audiograph.ComponentDescription{
+			Inputs: []audiograph.ComponentInput{
+				floatInput("in", "operand", 0),
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "out",
+					Description: result,
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+		operation: operation,
+	}
+}
+
+func (o *unaryOperator) GetDescription() *audiograph.ComponentDescription {
+	return &o.description
+}
+
+func (o *unaryOperator) Execute(ctx audiograph.ExecutionContext) error {
+	o.description.Outputs[0].Value.Float = o.operation(o.description.Inputs[0].Value.Float)
+
+	return nil
+}
+
+type Add struct{ binaryOperator }
+
+func NewAdd() *Add {
+	return &Add{newBinaryOperator("a + b", 0, func(a, b float64) float64 {
+		return a + b
+	})}
+}
+
+type Subtract struct{ binaryOperator }
+
+func NewSubtract() *Subtract {
+	return &Subtract{newBinaryOperator("a - b", 0, func(a, b float64) float64 {
+		return a - b
+	})}
+}
+
+// Multiply gives a ring modulation when fed with two audio signals.
+type Multiply struct{ binaryOperator }
+
+func NewMultiply() *Multiply {
+	return &Multiply{newBinaryOperator("a * b", 1, func(a, b float64) float64 {
+		return a * b
+	})}
+}
+
+// Divide outputs 0 rather than an infinity when b is 0.
+type Divide struct{ binaryOperator }
+
+func NewDivide() *Divide {
+	return &Divide{newBinaryOperator("a / b, or 0 when b is 0", 1, func(a, b float64) float64 {
+		if b == 0 {
+			return 0
+		}
+
+		return a / b
+	})}
+}
+
+type Min struct{ binaryOperator }
+
+func NewMin() *Min {
+	return &Min{newBinaryOperator("smallest of a and b", 0, math.Min)}
+}
+
+type Max struct{ binaryOperator }
+
+func NewMax() *Max {
+	return &Max{newBinaryOperator("largest of a and b", 0, math.Max)}
+}
+
+type Abs struct{ unaryOperator }
+
+func NewAbs() *Abs {
+	return &Abs{newUnaryOperator("absolute value of the input", math.Abs)}
+}
+
+// Invert flips the polarity of its input.
+type Invert struct{ unaryOperator }
+
+func NewInvert() *Invert {
+	return &Invert{newUnaryOperator("opposite of the input", func(in float64) float64 {
+		return -in
+	})}
+}
@@ -0,0 +1,215 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+var (
+	ErrUnknownFilterMode = fmt.Errorf("unknown filter mode")
+)
+
+const (
+	// Largest boost or cut, in dB, of the peak and shelf modes
+	biquadMaxGain = 48.0
+)
+
+// A biquadDesign computes the coefficients b0, b1, b2, a0, a1, a2 of the RBJ
+// cookbook filters, w0 being the normalized angular cutoff, alpha derived from
+// q and a the amplitude derived from the gain.
+type biquadDesign func(w0, alpha, a float64) [6]float64
+
+var (
+	biquadDesigns = map[string]biquadDesign{
+		"lowpass": func(w0, alpha, a float64) [6]float64 {
+			cos := math.Cos(w0)
+			return [6]float64{(1 - cos) / 2, 1 - cos, (1 - cos) / 2, 1 + alpha, -2 * cos, 1 - alpha}
+		},
+		"highpass": func(w0, alpha, a float64) [6]float64 {
+			cos := math.Cos(w0)
+			return [6]float64{(1 + cos) / 2, -(1 + cos), (1 + cos) / 2, 1 + alpha, -2 * cos, 1 - alpha}
+		},
+		// Constant 0 dB peak gain
+		"bandpass": func(w0, alpha, a float64) [6]float64 {
+			cos := math.Cos(w0)
+			return [6]float64{alpha, 0, -alpha, 1 + alpha, -2 * cos, 1 - alpha}
+		},
+		"notch": func(w0, alpha, a float64) [6]float64 {
+			cos := math.Cos(w0)
+			return [6]float64{1, -2 * cos, 1, 1 + alpha, -2 * cos, 1 - alpha}
+		},
+		"peak": func(w0, alpha, a float64) [6]float64 {
+			cos := math.Cos(w0)
+			return [6]float64{1 + alpha*a, -2 * cos, 1 - alpha*a, 1 + alpha/a, -2 * cos, 1 - alpha/a}
+		},
+		"lowshelf": func(w0, alpha, a float64) [6]float64 {
+			cos := math.Cos(w0)
+			sqrtA := 2 * math.Sqrt(a) * alpha
+			return [6]float64{
+				a * ((a + 1) - (a-1)*cos + sqrtA),
+				2 * a * ((a - 1) - (a+1)*cos),
+				a * ((a + 1) - (a-1)*cos - sqrtA),
+				(a + 1) + (a-1)*cos + sqrtA,
+				-2 * ((a - 1) + (a+1)*cos),
+				(a + 1) + (a-1)*cos - sqrtA,
+			}
+		},
+		"highshelf": func(w0, alpha, a float64) [6]float64 {
+			cos := math.Cos(w0)
+			sqrtA := 2 * math.Sqrt(a) * alpha
+			return [6]float64{
+				a * ((a + 1) + (a-1)*cos + sqrtA),
+				-2 * a * ((a - 1) + (a+1)*cos),
+				a * ((a + 1) + (a-1)*cos - sqrtA),
+				(a + 1) - (a-1)*cos + sqrtA,
+				2 * ((a - 1) - (a+1)*cos),
+				(a + 1) - (a-1)*cos - sqrtA,
+			}
+		},
+		"allpass": func(w0, alpha, a float64) [6]float64 {
+			cos := math.Cos(w0)
+			return [6]float64{1 - alpha, -2 * cos, 1 + alpha, 1 + alpha, -2 * cos, 1 - alpha}
+		},
+	}
+)
+
+// Biquad is a second order filter using the coefficients of Robert
+// Bristow-Johnson's audio EQ cookbook.
+type Biquad struct {
+	description audiograph.ComponentDescription
+	design      biquadDesign
+
+	// Inputs the coefficients were computed for
+	cutoff, q, gain   float64
+	samplingFrequency uint32
+
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func NewBiquad() *Biquad {
+	return &Biquad{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				{
+					Name:        "mode",
+					Description: "lowpass (default), highpass, bandpass, notch, peak, lowshelf, highshelf or allpass",
+					Value: audiograph.Value{
+						Type:   audiograph.StringValueType,
+						String: "lowpass",
+					},
+				},
+			},
+			Inputs: []audiograph.ComponentInput{
+				{
+					Name:        "in",
+					Description: "signal to filter",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "cutoff",
+					Description: "cutoff or center frequency, in Hz",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 1000.0,
+					},
+				},
+				{
+					Name:        "q",
+					Description: "quality factor. higher values give a narrower band or a resonant peak. min 0.01",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: math.Sqrt2 / 2,
+					},
+				},
+				{
+					Name:        "gain",
+					Description: "gain of the peak and shelf modes, in dB. from -48 to 48",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "out",
+					Description: "filtered signal",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+		design: biquadDesigns["lowpass"],
+	}
+}
+
+func (b *Biquad) GetDescription() *audiograph.ComponentDescription {
+	return &b.description
+}
+
+func (b *Biquad) OnParameterChange(name string) error {
+	if name != "mode" {
+		return nil
+	}
+
+	mode := b.description.Parameters[0].Value.String
+
+	design, ok := biquadDesigns[strings.ToLower(mode)]
+	if !ok {
+		return fmt.Errorf("filter mode '%s': %w", mode, ErrUnknownFilterMode)
+	}
+
+	b.design = design
+	// Forces the coefficients to be computed again
+	b.samplingFrequency = 0
+
+	return nil
+}
+
+func (b *Biquad) Execute(ctx audiograph.ExecutionContext) error {
+	in := b.description.Inputs[0].Value.Float
+	cutoff := b.description.Inputs[1].Value.Float
+	q := b.description.Inputs[2].Value.Float
+	gain := b.description.Inputs[3].Value.Float
+
+	if cutoff != b.cutoff || q != b.q || gain != b.gain || ctx.SamplingFrequency != b.samplingFrequency {
+		b.cutoff, b.q, b.gain, b.samplingFrequency = cutoff, q, gain, ctx.SamplingFrequency
+		b.updateCoefficients()
+	}
+
+	out := b.b0*in + b.b1*b.x1 + b.b2*b.x2 - b.a1*b.y1 - b.a2*b.y2
+
+	b.x2, b.x1 = b.x1, in
+	b.y2, b.y1 = b.y1, out
+
+	b.description.Outputs[0].Value.Float = out
+
+	return nil
+}
+
+func (b *Biquad) updateCoefficients() {
+	sr := float64(b.samplingFrequency)
+
+	// Keeps the cutoff strictly between 0 and the Nyquist frequency, where the
+	// cookbook formulas are defined
+	cutoff := clamp(b.cutoff, 1, sr*0.49)
+	q := math.Max(b.q, 0.01)
+
+	w0 := 2 * math.Pi * cutoff / sr
+	alpha := math.Sin(w0) / (2 * q)
+	a := math.Pow(10, clamp(b.gain, -biquadMaxGain, biquadMaxGain)/40)
+
+	c := b.design(w0, alpha, a)
+
+	b.b0 = c[0] / c[3]
+	b.b1 = c[1] / c[3]
+	b.b2 = c[2] / c[3]
+	b.a1 = c[4] / c[3]
+	b.a2 = c[5] / c[3]
+}
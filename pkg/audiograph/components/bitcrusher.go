@@ -0,0 +1,67 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// Bitcrusher degrades its input by quantizing it to fewer bits and holding it
+// to emulate a lower sampling rate, without any filtering so that the aliasing
+// is kept.
+type Bitcrusher struct {
+	description audiograph.ComponentDescription
+
+	// phase goes from 0 to 1 between two samples at the reduced rate
+	phase float64
+	held  float64
+}
+
+func NewBitcrusher() *Bitcrusher {
+	return &Bitcrusher{
+		description: audiograph.ComponentDescription{
+			Inputs: []audiograph.ComponentInput{
+				floatInput("in", "signal to crush", 0),
+				floatInput("bits", "bit depth the signal is quantized to. fractional depths are allowed. from 1 to 24", 8),
+				floatInput("rate", "sampling rate the signal is held at, in Hz. from 1 to the sampling frequency", 8000),
+				floatInput("mix", "balance between the dry (0) and the crushed (1) signals", 1),
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "out",
+					Description: "crushed signal",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+		// Samples the first input right away
+		phase: 1,
+	}
+}
+
+func (b *Bitcrusher) GetDescription() *audiograph.ComponentDescription {
+	return &b.description
+}
+
+func (b *Bitcrusher) Execute(ctx audiograph.ExecutionContext) error {
+	in := b.description.Inputs[0].Value.Float
+	bits := clamp(b.description.Inputs[1].Value.Float, 1, 24)
+	rate := clamp(b.description.Inputs[2].Value.Float, 1, float64(ctx.SamplingFrequency))
+	mix := clamp(b.description.Inputs[3].Value.Float, 0, 1)
+
+	if b.phase >= 1 {
+		b.phase -= math.Floor(b.phase)
+
+		// 1 bit leaves a single step on each side of 0
+		steps := math.Pow(2, bits-1)
+		b.held = math.Round(in*steps) / steps
+	}
+
+	b.phase += rate / float64(ctx.SamplingFrequency)
+
+	b.description.Outputs[0].Value.Float = in*(1-mix) + b.held*mix
+
+	return nil
+}
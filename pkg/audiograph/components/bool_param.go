@@ -0,0 +1,41 @@
+package components
+
+import "github.com/sywesk/audiomix/pkg/audiograph"
+
+type BoolParam struct {
+	description audiograph.ComponentDescription
+}
+
+func NewBoolParam() *BoolParam {
+	return &BoolParam{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				{
+					Name:        "value",
+					Description: "desired bool value",
+					Value: audiograph.Value{
+						Type: audiograph.BoolValueType,
+					},
+				},
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "bool",
+					Description: "desired bool value",
+					Value: audiograph.Value{
+						Type: audiograph.BoolValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (b *BoolParam) GetDescription() *audiograph.ComponentDescription {
+	return &b.description
+}
+
+func (b *BoolParam) Execute(ctx audiograph.ExecutionContext) error {
+	b.description.Outputs[0].Value.Bool = b.description.Parameters[0].Value.Bool
+	return nil
+}
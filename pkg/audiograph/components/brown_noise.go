@@ -0,0 +1,50 @@
+package components
+
+import (
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// BrownNoise generates noise whose power decreases by 6 dB per octave, by
+// integrating white noise with a slight leak to keep it centered.
+type BrownNoise struct {
+	description audiograph.ComponentDescription
+	source      noiseSource
+	last        float64
+}
+
+func NewBrownNoise() *BrownNoise {
+	return &BrownNoise{
+		description: noiseDescription("brown"),
+		source:      newNoiseSource(),
+	}
+}
+
+func (b *BrownNoise) GetDescription() *audiograph.ComponentDescription {
+	return &b.description
+}
+
+func (b *BrownNoise) OnParameterChange(name string) error {
+	if name == "seed" {
+		b.source.seedFromParameter(b.description.Parameters[0].Value.Integer)
+		b.last = 0
+	}
+
+	return nil
+}
+
+func (b *BrownNoise) Seed(seed int64) {
+	b.source.seedFromGraph(seed)
+	b.last = 0
+}
+
+func (b *BrownNoise) Execute(ctx audiograph.ExecutionContext) error {
+	gain := b.description.Inputs[0].Value.Float
+	offset := b.description.Inputs[1].Value.Float
+
+	b.last = (b.last + 0.02*b.source.next()) / 1.02
+
+	// Brings the output roughly between -1 and 1
+	b.description.Outputs[0].Value.Float = b.last*3.5*gain + offset
+
+	return nil
+}
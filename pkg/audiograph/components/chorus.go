@@ -0,0 +1,40 @@
+package components
+
+import (
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+const (
+	// Delay, in seconds, around which the chorus sweeps
+	chorusCenter = 0.02
+	// Largest distance, in seconds, of the delay from the center
+	chorusSwing = 0.01
+)
+
+// Chorus thickens the signal by mixing it with copies delayed by a slowly
+// swept 10 to 30 ms.
+type Chorus struct {
+	description audiograph.ComponentDescription
+	delay       modulatedDelay
+}
+
+func NewChorus() *Chorus {
+	return &Chorus{
+		description: modulationDescription("chorus", 0.8, 0),
+	}
+}
+
+func (c *Chorus) GetDescription() *audiograph.ComponentDescription {
+	return &c.description
+}
+
+// Prepare allocates the delay lines for the sampling frequency of the graph.
+func (c *Chorus) Prepare(samplingFrequency uint32) {
+	c.delay.prepare(samplingFrequency, chorusCenter, chorusSwing)
+}
+
+func (c *Chorus) Execute(ctx audiograph.ExecutionContext) error {
+	c.delay.process(&c.description, ctx, chorusCenter, chorusSwing)
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// Clamp limits its input to a range. Its bounds are swapped when min is above
+// max.
+type Clamp struct {
+	description audiograph.ComponentDescription
+}
+
+func NewClamp() *Clamp {
+	return &Clamp{
+		description: audiograph.ComponentDescription{
+			Inputs: []audiograph.ComponentInput{
+				floatInput("in", "signal to clamp", 0),
+				floatInput("min", "lowest output value", -1),
+				floatInput("max", "highest output value", 1),
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "out",
+					Description: "clamped signal",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (c *Clamp) GetDescription() *audiograph.ComponentDescription {
+	return &c.description
+}
+
+func (c *Clamp) Execute(ctx audiograph.ExecutionContext) error {
+	in := c.description.Inputs[0].Value.Float
+	low := c.description.Inputs[1].Value.Float
+	high := c.description.Inputs[2].Value.Float
+
+	c.description.Outputs[0].Value.Float = clamp(in, math.Min(low, high), math.Max(low, high))
+
+	return nil
+}
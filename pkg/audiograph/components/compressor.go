@@ -0,0 +1,73 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// Compressor reduces the level of the signal above its threshold, by the given
+// ratio.
+type Compressor struct {
+	description audiograph.ComponentDescription
+	smoother    gainSmoother
+}
+
+func NewCompressor() *Compressor {
+	return &Compressor{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				externalSidechainParameter(),
+			},
+			Inputs: []audiograph.ComponentInput{
+				floatInput("in", "signal to compress", 0),
+				floatInput("sidechain", "signal whose level drives the compression, when external_sidechain is true", 0),
+				floatInput("threshold", "level above which the signal is compressed, in dB", -20),
+				floatInput("ratio", "how many dB above the threshold give 1 dB above it at the output. min 1", 4),
+				floatInput("attack", "time for the compression to set in, in seconds", 0.01),
+				floatInput("release", "time for the compression to recover, in seconds", 0.1),
+				floatInput("knee", "width of the range around the threshold where the ratio gradually sets in, in dB", 6),
+				floatInput("makeup", "gain applied after the compression, in dB", 0),
+			},
+			Outputs: dynamicsOutputs(),
+		},
+	}
+}
+
+func (c *Compressor) GetDescription() *audiograph.ComponentDescription {
+	return &c.description
+}
+
+func (c *Compressor) Execute(ctx audiograph.ExecutionContext) error {
+	in := c.description.Inputs[0].Value.Float
+	threshold := c.description.Inputs[2].Value.Float
+	ratio := math.Max(c.description.Inputs[3].Value.Float, 1)
+	attack := c.description.Inputs[4].Value.Float
+	release := c.description.Inputs[5].Value.Float
+	knee := math.Max(c.description.Inputs[6].Value.Float, 0)
+	makeup := c.description.Inputs[7].Value.Float
+
+	detected := in
+	if c.description.Parameters[0].Value.Bool {
+		detected = c.description.Inputs[1].Value.Float
+	}
+
+	level := toDecibels(detected)
+	over := level - threshold
+
+	// Static curve with a quadratic knee, as described by Giannoulis, Massberg
+	// and Reiss
+	target := 0.0
+	if 2*over > knee {
+		target = over * (1 - 1/ratio)
+	} else if 2*math.Abs(over) <= knee && knee > 0 {
+		target = (1 - 1/ratio) * (over + knee/2) * (over + knee/2) / (2 * knee)
+	}
+
+	reduction := c.smoother.smooth(target, attack, release, ctx.SamplingFrequency)
+
+	c.description.Outputs[0].Value.Float = in * fromDecibels(makeup-reduction)
+	c.description.Outputs[1].Value.Float = reduction
+
+	return nil
+}
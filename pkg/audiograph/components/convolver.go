@@ -0,0 +1,305 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+	"github.com/sywesk/audiomix/pkg/wav"
+)
+
+const (
+	// convolverBlockSize is the size of the partitions of the impulse response,
+	// which is also the latency of the reverberated signal.
+	convolverBlockSize = 256
+	// Longest predelay, in seconds
+	convolverMaxPredelay = 0.5
+)
+
+var (
+	ErrEmptyImpulseResponse = fmt.Errorf("empty impulse response")
+)
+
+// convolverChannel convolves a signal with an impulse response using uniformly
+// partitioned overlap-save: the impulse response is cut into blocks whose
+// spectra are multiplied with the spectra of the last input blocks, so the
+// cost per sample grows with the length of the response divided by the block
+// size.
+type convolverChannel struct {
+	// partitions holds the spectra of the blocks of the impulse response, each
+	// zero-padded to twice the block size
+	partitions [][]complex128
+	// history holds the spectra of the last input blocks, history[position]
+	// being the latest one
+	history  [][]complex128
+	position int
+
+	previous []float64
+	input    []float64
+	output   []float64
+	spectrum []complex128
+}
+
+// convolverPartitions cuts an impulse response into blocks, and returns their
+// spectra.
+func convolverPartitions(impulseResponse []float64) [][]complex128 {
+	partitions := make([][]complex128, (len(impulseResponse)+convolverBlockSize-1)/convolverBlockSize)
+
+	for i := range partitions {
+		partition := make([]complex128, 2*convolverBlockSize)
+		for j := 0; j < convolverBlockSize && i*convolverBlockSize+j < len(impulseResponse); j++ {
+			partition[j] = complex(impulseResponse[i*convolverBlockSize+j], 0)
+		}
+
+		fft(partition, false)
+		partitions[i] = partition
+	}
+
+	return partitions
+}
+
+func newConvolverChannel(partitions [][]complex128) *convolverChannel {
+	c := &convolverChannel{
+		partitions: partitions,
+		history:    make([][]complex128, len(partitions)),
+		previous:   make([]float64, convolverBlockSize),
+		input:      make([]float64, convolverBlockSize),
+		output:     make([]float64, convolverBlockSize),
+		spectrum:   make([]complex128, 2*convolverBlockSize),
+	}
+
+	for i := range c.history {
+		c.history[i] = make([]complex128, 2*convolverBlockSize)
+	}
+
+	return c
+}
+
+// processBlock convolves the block of samples held by input, the result being
+// available in output until the next block is processed.
+func (c *convolverChannel) processBlock() {
+	c.position = (c.position + 1) % len(c.history)
+
+	latest := c.history[c.position]
+	for i, sample := range c.previous {
+		latest[i] = complex(sample, 0)
+	}
+	for i, sample := range c.input {
+		latest[convolverBlockSize+i] = complex(sample, 0)
+	}
+	copy(c.previous, c.input)
+
+	fft(latest, false)
+
+	// The signals are real, so only the first half of the spectrum needs to be
+	// computed, the second one being its conjugate
+	for bin := 0; bin <= convolverBlockSize; bin++ {
+		c.spectrum[bin] = 0
+	}
+
+	for i, partition := range c.partitions {
+		history := c.history[(c.position-i+len(c.history))%len(c.history)]
+
+		for bin := 0; bin <= convolverBlockSize; bin++ {
+			c.spectrum[bin] += history[bin] * partition[bin]
+		}
+	}
+
+	for bin := 1; bin < convolverBlockSize; bin++ {
+		c.spectrum[2*convolverBlockSize-bin] = cmplx.Conj(c.spectrum[bin])
+	}
+
+	fft(c.spectrum, true)
+
+	// The first half is wrapped around by the circular convolution
+	for i := range c.output {
+		c.output[i] = real(c.spectrum[convolverBlockSize+i])
+	}
+}
+
+// Convolver reverberates its input with the impulse response of a real space,
+// or of any other linear system, read from a WAV file. A stereo response gives
+// one response per channel.
+type Convolver struct {
+	description audiograph.ComponentDescription
+
+	// audio is the impulse response as decoded, channels convolve the left and
+	// right inputs with its first two channels resampled to samplingFrequency
+	audio             *wav.Audio
+	channels          []*convolverChannel
+	samplingFrequency uint32
+	position          int
+
+	predelay    [2]*delayLine
+	predelayTap [2]delayTap
+}
+
+func NewConvolver() *Convolver {
+	return &Convolver{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				{
+					Name:        "path",
+					Description: "path of the WAV file holding the impulse response, mono or stereo",
+					Value: audiograph.Value{
+						Type: audiograph.StringValueType,
+					},
+				},
+			},
+			Inputs: []audiograph.ComponentInput{
+				{
+					Name:        "left",
+					Description: "left channel",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "right",
+					Description: "right channel",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "predelay",
+					Description: "delay before the reverberation starts, in seconds. from 0 to 0.5",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "mix",
+					Description: "balance between the dry (0) and the reverberated (1) signals",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 0.3,
+					},
+				},
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "left",
+					Description: "left channel",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "right",
+					Description: "right channel",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (c *Convolver) GetDescription() *audiograph.ComponentDescription {
+	return &c.description
+}
+
+func (c *Convolver) OnParameterChange(name string) error {
+	if name != "path" {
+		return nil
+	}
+
+	audio, err := wav.ReadFile(c.description.Parameters[0].Value.String)
+	if err != nil {
+		return fmt.Errorf("failed to load impulse response: %w", err)
+	}
+
+	if audio.Frames() == 0 {
+		return fmt.Errorf("failed to load impulse response: %w", ErrEmptyImpulseResponse)
+	}
+
+	c.audio = audio
+	c.channels = nil
+	c.samplingFrequency = 0
+
+	return nil
+}
+
+func (c *Convolver) Execute(ctx audiograph.ExecutionContext) error {
+	left := c.description.Inputs[0].Value.Float
+	right := c.description.Inputs[1].Value.Float
+	predelay := clamp(c.description.Inputs[2].Value.Float, 0, convolverMaxPredelay)
+	mix := clamp(c.description.Inputs[3].Value.Float, 0, 1)
+
+	if c.audio == nil {
+		c.description.Outputs[0].Value.Float = left * (1 - mix)
+		c.description.Outputs[1].Value.Float = right * (1 - mix)
+		return nil
+	}
+
+	// Only happens when the component is used outside of a graph, which
+	// otherwise calls Prepare beforehand
+	if ctx.SamplingFrequency != c.samplingFrequency {
+		c.prepare(ctx.SamplingFrequency)
+	}
+
+	// The convolution already delays by a block, which is taken out of the
+	// predelay
+	delay := predelay*float64(ctx.SamplingFrequency) - convolverBlockSize
+
+	inputs := [2]float64{left, right}
+	for i, in := range inputs {
+		delayed := c.predelayTap[i].read(c.predelay[i], delay)
+		c.predelay[i].write(in)
+		if delay >= 1 {
+			inputs[i] = delayed
+		}
+	}
+
+	wetLeft := c.channels[0].output[c.position]
+	wetRight := c.channels[1].output[c.position]
+
+	c.channels[0].input[c.position] = inputs[0]
+	c.channels[1].input[c.position] = inputs[1]
+
+	c.position++
+	if c.position == convolverBlockSize {
+		c.position = 0
+		for _, channel := range c.channels {
+			channel.processBlock()
+		}
+	}
+
+	c.description.Outputs[0].Value.Float = left*(1-mix) + wetLeft*mix
+	c.description.Outputs[1].Value.Float = right*(1-mix) + wetRight*mix
+
+	return nil
+}
+
+// Prepare resamples the impulse response and computes its partitions ahead of
+// the playback, which is too slow for the audio path with long responses.
+func (c *Convolver) Prepare(samplingFrequency uint32) {
+	if c.audio == nil || c.samplingFrequency == samplingFrequency {
+		return
+	}
+
+	c.prepare(samplingFrequency)
+}
+
+// prepare resamples the impulse response and computes its partitions for the
+// given sampling frequency.
+func (c *Convolver) prepare(samplingFrequency uint32) {
+	left := convolverPartitions(resample(c.audio.Channels[0], c.audio.SampleRate, samplingFrequency))
+
+	// A mono response is used for both channels
+	right := left
+	if len(c.audio.Channels) > 1 {
+		right = convolverPartitions(resample(c.audio.Channels[1], c.audio.SampleRate, samplingFrequency))
+	}
+
+	c.channels = []*convolverChannel{newConvolverChannel(left), newConvolverChannel(right)}
+
+	maxDelay := int(math.Ceil(convolverMaxPredelay * float64(samplingFrequency)))
+	c.predelay = [2]*delayLine{newDelayLine(maxDelay), newDelayLine(maxDelay)}
+
+	c.samplingFrequency = samplingFrequency
+	c.position = 0
+}
@@ -0,0 +1,78 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// Crossfade blends two signals, following one of the pan laws.
+type Crossfade struct {
+	description audiograph.ComponentDescription
+	law         panLaw
+}
+
+func NewCrossfade() *Crossfade {
+	return &Crossfade{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				{
+					Name:        "law",
+					Description: "crossfade law: linear (default), constant-power or -4.5dB",
+					Value: audiograph.Value{
+						Type:   audiograph.StringValueType,
+						String: "linear",
+					},
+				},
+			},
+			Inputs: []audiograph.ComponentInput{
+				floatInput("a", "signal output when position is 0", 0),
+				floatInput("b", "signal output when position is 1", 0),
+				floatInput("position", "balance between a and b. from 0 to 1", 0.5),
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "out",
+					Description: "blend of a and b",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+		law: panLaws["linear"],
+	}
+}
+
+func (c *Crossfade) GetDescription() *audiograph.ComponentDescription {
+	return &c.description
+}
+
+func (c *Crossfade) OnParameterChange(name string) error {
+	if name != "law" {
+		return nil
+	}
+
+	lawName := c.description.Parameters[0].Value.String
+
+	law, ok := panLaws[strings.ToLower(lawName)]
+	if !ok {
+		return fmt.Errorf("crossfade law '%s': %w", lawName, ErrUnknownPanLaw)
+	}
+
+	c.law = law
+	return nil
+}
+
+func (c *Crossfade) Execute(ctx audiograph.ExecutionContext) error {
+	a := c.description.Inputs[0].Value.Float
+	b := c.description.Inputs[1].Value.Float
+	position := clamp(c.description.Inputs[2].Value.Float, 0, 1)
+
+	gainA, gainB := c.law(position)
+
+	c.description.Outputs[0].Value.Float = a*gainA + b*gainB
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// Delay repeats its input after a modulatable time, reading its delay line
+// between samples so that the time can be swept smoothly.
+type Delay struct {
+	description audiograph.ComponentDescription
+
+	line              *delayLine
+	tap               delayTap
+	samplingFrequency uint32
+}
+
+func NewDelay() *Delay {
+	return &Delay{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				{
+					Name:        "max_time",
+					Description: "longest delay time, in seconds, which sizes the buffer. defaults to 1",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 1.0,
+					},
+				},
+				{
+					Name:        "interpolation",
+					Description: "how the buffer is read between samples: linear (default), cubic or allpass",
+					Value: audiograph.Value{
+						Type:   audiograph.StringValueType,
+						String: "linear",
+					},
+				},
+			},
+			Inputs: []audiograph.ComponentInput{
+				{
+					Name:        "in",
+					Description: "signal to delay",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "time",
+					Description: "delay time, in seconds. from 0 to max_time",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 0.25,
+					},
+				},
+				{
+					Name:        "feedback",
+					Description: "amount of the delayed signal sent back into the delay. from -1 to 1",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "mix",
+					Description: "balance between the dry (0) and the delayed (1) signals",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 0.5,
+					},
+				},
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "out",
+					Description: "mix of the dry and delayed signals",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *Delay) GetDescription() *audiograph.ComponentDescription {
+	return &d.description
+}
+
+func (d *Delay) OnParameterChange(name string) error {
+	switch name {
+	case "max_time":
+		// The buffer is sized again by Prepare
+		d.line = nil
+	case "interpolation":
+		interpolation, err := parseInterpolation(d.description.Parameters[1].Value.String)
+		if err != nil {
+			return err
+		}
+
+		d.tap = delayTap{interpolation: interpolation}
+	}
+
+	return nil
+}
+
+// Prepare allocates the delay line, sized for the longest delay time at the
+// sampling frequency of the graph.
+func (d *Delay) Prepare(samplingFrequency uint32) {
+	if d.line != nil && d.samplingFrequency == samplingFrequency {
+		return
+	}
+
+	maxTime := math.Max(d.description.Parameters[0].Value.Float, 0)
+
+	d.samplingFrequency = samplingFrequency
+	d.line = newDelayLine(int(math.Ceil(maxTime * float64(samplingFrequency))))
+}
+
+func (d *Delay) Execute(ctx audiograph.ExecutionContext) error {
+	in := d.description.Inputs[0].Value.Float
+	time := d.description.Inputs[1].Value.Float
+	feedback := clamp(d.description.Inputs[2].Value.Float, -1, 1)
+	mix := clamp(d.description.Inputs[3].Value.Float, 0, 1)
+
+	// Only happens when the component is used outside of a graph, which
+	// otherwise calls Prepare beforehand
+	d.Prepare(ctx.SamplingFrequency)
+
+	wet := d.tap.read(d.line, time*float64(ctx.SamplingFrequency))
+	d.line.write(in + wet*feedback)
+
+	d.description.Outputs[0].Value.Float = in*(1-mix) + wet*mix
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+var (
+	ErrUnknownInterpolation = fmt.Errorf("unknown interpolation")
+)
+
+type interpolation int
+
+const (
+	linearInterpolation interpolation = iota
+	cubicInterpolation
+	allpassInterpolation
+)
+
+func parseInterpolation(name string) (interpolation, error) {
+	switch strings.ToLower(name) {
+	case "linear":
+		return linearInterpolation, nil
+	case "cubic":
+		return cubicInterpolation, nil
+	case "allpass":
+		return allpassInterpolation, nil
+	default:
+		return 0, fmt.Errorf("interpolation '%s': %w", name, ErrUnknownInterpolation)
+	}
+}
+
+// delayLine is a circular buffer holding the last written samples.
+type delayLine struct {
+	buffer   []float64
+	position int
+}
+
+// newDelayLine returns a delay line able to delay by maxDelay samples, with
+// room for the interpolation.
+func newDelayLine(maxDelay int) *delayLine {
+	return &delayLine{
+		buffer: make([]float64, maxDelay+4),
+	}
+}
+
+func (d *delayLine) write(value float64) {
+	d.buffer[d.position] = value
+
+	d.position++
+	if d.position == len(d.buffer) {
+		d.position = 0
+	}
+}
+
+// at returns the sample written the given number of samples ago, 1 being the
+// last written sample.
+func (d *delayLine) at(ago int) float64 {
+	if ago < 1 {
+		ago = 1
+	} else if ago >= len(d.buffer) {
+		ago = len(d.buffer) - 1
+	}
+
+	index := d.position - ago
+	if index < 0 {
+		index += len(d.buffer)
+	}
+
+	return d.buffer[index]
+}
+
+// maxDelay returns the longest delay, in samples, that can be read.
+func (d *delayLine) maxDelay() float64 {
+	return float64(len(d.buffer) - 4)
+}
+
+// delayTap reads a delay line at a fractional delay. The allpass interpolation
+// is recursive, so each read position of a line needs its own tap.
+type delayTap struct {
+	interpolation interpolation
+
+	allpassInput  float64
+	allpassOutput float64
+}
+
+// read returns the sample written delay samples ago. The shortest delay is 1
+// sample, 1.5 with the allpass interpolation and 2 with the cubic one, which
+// needs a sample on each side.
+func (t *delayTap) read(line *delayLine, delay float64) float64 {
+	delay = clamp(delay, 1, line.maxDelay())
+
+	switch t.interpolation {
+	case cubicInterpolation:
+		delay = math.Max(delay, 2)
+		n := int(delay)
+		frac := delay - float64(n)
+
+		return hermite(line.at(n-1), line.at(n), line.at(n+1), line.at(n+2), frac)
+	case allpassInterpolation:
+		// The first order allpass delays by (1-eta)/(1+eta) samples, which is
+		// kept between 0.5 and 1.5 where its phase delay is the flattest
+		delay = math.Max(delay, 1.5)
+		n := int(math.Floor(delay - 0.5))
+		frac := delay - float64(n)
+		eta := (1 - frac) / (1 + frac)
+
+		input := line.at(n)
+		t.allpassOutput = eta*input + t.allpassInput - eta*t.allpassOutput
+		t.allpassInput = input
+
+		return t.allpassOutput
+	default:
+		n := int(delay)
+		frac := delay - float64(n)
+
+		return line.at(n)*(1-frac) + line.at(n+1)*frac
+	}
+}
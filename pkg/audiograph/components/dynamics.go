@@ -0,0 +1,88 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// Levels below this one, in dB, are considered silent by the dynamics
+// processors.
+const dynamicsFloor = -120.0
+
+func toDecibels(value float64) float64 {
+	return math.Max(20*math.Log10(math.Abs(value)), dynamicsFloor)
+}
+
+func fromDecibels(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+// timeCoefficient returns the coefficient of a one-pole smoother reaching 63%
+// of its target in the given time, in seconds.
+func timeCoefficient(seconds float64, samplingFrequency uint32) float64 {
+	if seconds <= 0 {
+		return 0
+	}
+
+	return math.Exp(-1 / (seconds * float64(samplingFrequency)))
+}
+
+// gainSmoother smooths a gain reduction, in dB, using the attack time when the
+// reduction increases and the release time when it decreases.
+type gainSmoother struct {
+	reduction float64
+
+	attack, release         float64
+	attackCoef, releaseCoef float64
+	samplingFrequency       uint32
+}
+
+func (g *gainSmoother) smooth(target, attack, release float64, samplingFrequency uint32) float64 {
+	if attack != g.attack || release != g.release || samplingFrequency != g.samplingFrequency {
+		g.attack, g.release, g.samplingFrequency = attack, release, samplingFrequency
+		g.attackCoef = timeCoefficient(attack, samplingFrequency)
+		g.releaseCoef = timeCoefficient(release, samplingFrequency)
+	}
+
+	coef := g.releaseCoef
+	if target > g.reduction {
+		coef = g.attackCoef
+	}
+
+	g.reduction = target + (g.reduction-target)*coef
+
+	return g.reduction
+}
+
+// externalSidechainParameter is the parameter of the dynamics processors
+// selecting the signal their level is detected on.
+func externalSidechainParameter() audiograph.ComponentParameter {
+	return audiograph.ComponentParameter{
+		Name:        "external_sidechain",
+		Description: "when true, the level is detected on the sidechain input instead of the processed signal",
+		Value: audiograph.Value{
+			Type: audiograph.BoolValueType,
+		},
+	}
+}
+
+// dynamicsOutputs returns the outputs shared by the dynamics processors.
+func dynamicsOutputs() []audiograph.ComponentOutput {
+	return []audiograph.ComponentOutput{
+		{
+			Name:        "out",
+			Description: "processed signal",
+			Value: audiograph.Value{
+				Type: audiograph.FloatValueType,
+			},
+		},
+		{
+			Name:        "gain_reduction",
+			Description: "current gain reduction, in dB. 0 when the signal is left untouched",
+			Value: audiograph.Value{
+				Type: audiograph.FloatValueType,
+			},
+		},
+	}
+}
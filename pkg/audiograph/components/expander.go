@@ -0,0 +1,78 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// Expander reduces the level of the signal below its threshold, by the given
+// ratio, making quiet parts quieter.
+type Expander struct {
+	description audiograph.ComponentDescription
+	smoother    gainSmoother
+}
+
+func NewExpander() *Expander {
+	return &Expander{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				externalSidechainParameter(),
+			},
+			Inputs: []audiograph.ComponentInput{
+				floatInput("in", "signal to expand", 0),
+				floatInput("sidechain", "signal whose level drives the expansion, when external_sidechain is true", 0),
+				floatInput("threshold", "level below which the signal is expanded, in dB", -40),
+				floatInput("ratio", "how many dB below the threshold at the output for 1 dB below it at the input. min 1", 2),
+				floatInput("attack", "time for the signal to come back once above the threshold, in seconds", 0.001),
+				floatInput("release", "time for the expansion to set in, in seconds", 0.1),
+				floatInput("knee", "width of the range around the threshold where the ratio gradually sets in, in dB", 6),
+				floatInput("makeup", "gain applied after the expansion, in dB", 0),
+				floatInput("range", "largest attenuation of the signal, in dB. min 0", 40),
+			},
+			Outputs: dynamicsOutputs(),
+		},
+	}
+}
+
+func (e *Expander) GetDescription() *audiograph.ComponentDescription {
+	return &e.description
+}
+
+func (e *Expander) Execute(ctx audiograph.ExecutionContext) error {
+	in := e.description.Inputs[0].Value.Float
+	threshold := e.description.Inputs[2].Value.Float
+	ratio := math.Max(e.description.Inputs[3].Value.Float, 1)
+	attack := e.description.Inputs[4].Value.Float
+	release := e.description.Inputs[5].Value.Float
+	knee := math.Max(e.description.Inputs[6].Value.Float, 0)
+	makeup := e.description.Inputs[7].Value.Float
+	attenuation := math.Max(e.description.Inputs[8].Value.Float, 0)
+
+	detected := in
+	if e.description.Parameters[0].Value.Bool {
+		detected = e.description.Inputs[1].Value.Float
+	}
+
+	level := toDecibels(detected)
+	under := threshold - level
+
+	target := 0.0
+	if 2*under > knee {
+		target = under * (ratio - 1)
+	} else if 2*math.Abs(under) <= knee && knee > 0 {
+		target = (ratio - 1) * (under + knee/2) * (under + knee/2) / (2 * knee)
+	}
+
+	// Silence sits at the dB floor, far below any threshold
+	target = math.Min(target, attenuation)
+
+	// The gain reduction grows when the signal gets quieter, so the release of
+	// the expander is the attack of the smoother
+	reduction := e.smoother.smooth(target, release, attack, ctx.SamplingFrequency)
+
+	e.description.Outputs[0].Value.Float = in * fromDecibels(makeup-reduction)
+	e.description.Outputs[1].Value.Float = reduction
+
+	return nil
+}
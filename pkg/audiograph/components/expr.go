@@ -0,0 +1,95 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// Expr outputs the value of a formula computed for each sample, such as
+// sin(a*2) * b + 0.1. The variables a to h used by the formula become its
+// inputs, and sr and t give the sampling frequency and the time in seconds.
+type Expr struct {
+	description audiograph.ComponentDescription
+
+	node exprNode
+	env  exprEnv
+	// variables holds the variable of each input
+	variables []int
+	// samples counts the evaluations, from which t is derived
+	samples uint64
+}
+
+func NewExpr() *Expr {
+	return &Expr{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				{
+					Name:        "expr",
+					Description: "formula to compute, using +, -, *, /, %, ^, comparisons, math functions, pi, tau, sr, t and the variables a to h",
+					Value: audiograph.Value{
+						Type: audiograph.StringValueType,
+					},
+				},
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "out",
+					Description: "value of the formula. 0 until a formula is given",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (e *Expr) GetDescription() *audiograph.ComponentDescription {
+	return &e.description
+}
+
+func (e *Expr) OnParameterChange(name string) error {
+	if name != "expr" {
+		return nil
+	}
+
+	node, used, err := compileExpr(e.description.Parameters[0].Value.String)
+	if err != nil {
+		return err
+	}
+
+	e.node = node
+	e.variables = nil
+	e.description.Inputs = nil
+
+	for variable, ok := range used {
+		if !ok {
+			continue
+		}
+
+		e.variables = append(e.variables, variable)
+		e.description.Inputs = append(e.description.Inputs,
+			floatInput(string(rune('a'+variable)), fmt.Sprintf("value of %c in the formula", 'a'+variable), 0))
+	}
+
+	return nil
+}
+
+func (e *Expr) Execute(ctx audiograph.ExecutionContext) error {
+	if e.node == nil {
+		return nil
+	}
+
+	for i, variable := range e.variables {
+		e.env.vars[variable] = e.description.Inputs[i].Value.Float
+	}
+
+	e.env.sr = float64(ctx.SamplingFrequency)
+	e.env.t = float64(e.samples) / e.env.sr
+	e.samples++
+
+	e.description.Outputs[0].Value.Float = e.node(&e.env)
+
+	return nil
+}
@@ -0,0 +1,431 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"unicode"
+)
+
+var (
+	ErrInvalidExpression = fmt.Errorf("invalid expression")
+)
+
+const (
+	// exprVariables is the number of variables an expression can use, from a
+	// to h.
+	exprVariables = 8
+)
+
+// exprEnv holds the values an expression is evaluated with.
+type exprEnv struct {
+	vars [exprVariables]float64
+	// sr is the sampling frequency, and t the time elapsed since the first
+	// evaluation, in seconds
+	sr, t float64
+}
+
+// An exprNode evaluates a part of a compiled expression. Expressions are
+// compiled into a tree of closures, so that nothing is parsed nor looked up
+// while evaluating them.
+type exprNode func(env *exprEnv) float64
+
+type exprFunction struct {
+	arity int
+	build func(args []exprNode) exprNode
+}
+
+func unaryExprFunction(fn func(float64) float64) exprFunction {
+	return exprFunction{
+		arity: 1,
+		build: func(args []exprNode) exprNode {
+			x := args[0]
+			return func(env *exprEnv) float64 { return fn(x(env)) }
+		},
+	}
+}
+
+func binaryExprFunction(fn func(float64, float64) float64) exprFunction {
+	return exprFunction{
+		arity: 2,
+		build: func(args []exprNode) exprNode {
+			x, y := args[0], args[1]
+			return func(env *exprEnv) float64 { return fn(x(env), y(env)) }
+		},
+	}
+}
+
+func exprBool(value bool) float64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
+var (
+	exprFunctions = map[string]exprFunction{
+		"sin":   unaryExprFunction(math.Sin),
+		"cos":   unaryExprFunction(math.Cos),
+		"tan":   unaryExprFunction(math.Tan),
+		"asin":  unaryExprFunction(math.Asin),
+		"acos":  unaryExprFunction(math.Acos),
+		"atan":  unaryExprFunction(math.Atan),
+		"sinh":  unaryExprFunction(math.Sinh),
+		"cosh":  unaryExprFunction(math.Cosh),
+		"tanh":  unaryExprFunction(math.Tanh),
+		"exp":   unaryExprFunction(math.Exp),
+		"log":   unaryExprFunction(math.Log),
+		"log2":  unaryExprFunction(math.Log2),
+		"log10": unaryExprFunction(math.Log10),
+		"sqrt":  unaryExprFunction(math.Sqrt),
+		"abs":   unaryExprFunction(math.Abs),
+		"floor": unaryExprFunction(math.Floor),
+		"ceil":  unaryExprFunction(math.Ceil),
+		"round": unaryExprFunction(math.Round),
+		"frac":  unaryExprFunction(wrapPhase),
+		"sign": unaryExprFunction(func(x float64) float64 {
+			if x > 0 {
+				return 1
+			} else if x < 0 {
+				return -1
+			}
+
+			return 0
+		}),
+		"atan2": binaryExprFunction(math.Atan2),
+		"pow":   binaryExprFunction(math.Pow),
+		"min":   binaryExprFunction(math.Min),
+		"max":   binaryExprFunction(math.Max),
+		"mod":   binaryExprFunction(math.Mod),
+		"clamp": {
+			arity: 3,
+			build: func(args []exprNode) exprNode {
+				x, low, high := args[0], args[1], args[2]
+				return func(env *exprEnv) float64 { return clamp(x(env), low(env), high(env)) }
+			},
+		},
+		// if(condition, then, else), the condition being true when not 0
+		"if": {
+			arity: 3,
+			build: func(args []exprNode) exprNode {
+				condition, then, otherwise := args[0], args[1], args[2]
+				return func(env *exprEnv) float64 {
+					if condition(env) != 0 {
+						return then(env)
+					}
+
+					return otherwise(env)
+				}
+			},
+		},
+	}
+
+	// e is not a constant, it is one of the variables
+	exprConstants = map[string]float64{
+		"pi":  math.Pi,
+		"tau": 2 * math.Pi,
+	}
+
+	// Binary operators by precedence level, from the lowest. Comparisons give
+	// 1 when true and 0 when false.
+	exprOperators = []map[string]func(x, y float64) float64{
+		{
+			"<":  func(x, y float64) float64 { return exprBool(x < y) },
+			"<=": func(x, y float64) float64 { return exprBool(x <= y) },
+			">":  func(x, y float64) float64 { return exprBool(x > y) },
+			">=": func(x, y float64) float64 { return exprBool(x >= y) },
+			"==": func(x, y float64) float64 { return exprBool(x == y) },
+			"!=": func(x, y float64) float64 { return exprBool(x != y) },
+		},
+		{
+			"+": func(x, y float64) float64 { return x + y },
+			"-": func(x, y float64) float64 { return x - y },
+		},
+		{
+			"*": func(x, y float64) float64 { return x * y },
+			"/": func(x, y float64) float64 { return x / y },
+			"%": math.Mod,
+		},
+	}
+)
+
+// exprCompiler is a recursive descent parser turning an expression into a tree
+// of exprNode. The grammar, from the lowest precedence, is:
+//
+//	comparison := sum [("<" | "<=" | ">" | ">=" | "==" | "!=") sum]
+//	sum        := product {("+" | "-") product}
+//	product    := unary {("*" | "/" | "%") unary}
+//	unary      := ("-" | "+") unary | power
+//	power      := primary ["^" unary]
+//	primary    := number | identifier | identifier "(" arguments ")" | "(" comparison ")"
+type exprCompiler struct {
+	tokens   []string
+	position int
+	// used tells which of the variables a to h the expression reads
+	used [exprVariables]bool
+}
+
+// compileExpr compiles an expression, and returns which of the variables a to
+// h it uses.
+func compileExpr(source string) (exprNode, [exprVariables]bool, error) {
+	tokens, err := tokenizeExpr(source)
+	if err != nil {
+		return nil, [exprVariables]bool{}, err
+	}
+
+	compiler := &exprCompiler{tokens: tokens}
+
+	node, err := compiler.comparison()
+	if err != nil {
+		return nil, [exprVariables]bool{}, err
+	}
+
+	if compiler.position < len(tokens) {
+		return nil, [exprVariables]bool{}, fmt.Errorf("unexpected '%s': %w", tokens[compiler.position], ErrInvalidExpression)
+	}
+
+	return node, compiler.used, nil
+}
+
+// tokenizeExpr splits an expression into numbers, identifiers and operators.
+func tokenizeExpr(source string) ([]string, error) {
+	var tokens []string
+
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		start := i
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+			continue
+		case unicode.IsDigit(r) || r == '.':
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+
+			// Exponent, as in 1e-3
+			if i < len(runes) && (runes[i] == 'e' || runes[i] == 'E') {
+				i++
+				if i < len(runes) && (runes[i] == '-' || runes[i] == '+') {
+					i++
+				}
+				for i < len(runes) && unicode.IsDigit(runes[i]) {
+					i++
+				}
+			}
+		case unicode.IsLetter(r) || r == '_':
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+		case i+1 < len(runes) && (r == '<' || r == '>' || r == '=' || r == '!') && runes[i+1] == '=':
+			i += 2
+		case r == '<' || r == '>' || r == '+' || r == '-' || r == '*' || r == '/' || r == '%' || r == '^' || r == '(' || r == ')' || r == ',':
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' at %d: %w", r, i+1, ErrInvalidExpression)
+		}
+
+		tokens = append(tokens, string(runes[start:i]))
+	}
+
+	return tokens, nil
+}
+
+func (c *exprCompiler) peek() string {
+	if c.position >= len(c.tokens) {
+		return ""
+	}
+
+	return c.tokens[c.position]
+}
+
+func (c *exprCompiler) expect(token string) error {
+	if c.position >= len(c.tokens) {
+		return fmt.Errorf("missing '%s': %w", token, ErrInvalidExpression)
+	}
+
+	if c.tokens[c.position] != token {
+		return fmt.Errorf("expected '%s', got '%s': %w", token, c.tokens[c.position], ErrInvalidExpression)
+	}
+
+	c.position++
+	return nil
+}
+
+func (c *exprCompiler) comparison() (exprNode, error) {
+	return c.binary(0)
+}
+
+// binary parses the operators of the given precedence level, and those above.
+func (c *exprCompiler) binary(level int) (exprNode, error) {
+	operand := c.binary
+	if level == len(exprOperators)-1 {
+		operand = func(int) (exprNode, error) { return c.unary() }
+	}
+
+	left, err := operand(level + 1)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		operator, ok := exprOperators[level][c.peek()]
+		if !ok {
+			return left, nil
+		}
+
+		c.position++
+
+		right, err := operand(level + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		x, y := left, right
+		left = func(env *exprEnv) float64 { return operator(x(env), y(env)) }
+
+		// Comparisons cannot be chained
+		if level == 0 {
+			return left, nil
+		}
+	}
+}
+
+func (c *exprCompiler) unary() (exprNode, error) {
+	switch c.peek() {
+	case "-":
+		c.position++
+
+		operand, err := c.unary()
+		if err != nil {
+			return nil, err
+		}
+
+		return func(env *exprEnv) float64 { return -operand(env) }, nil
+	case "+":
+		c.position++
+		return c.unary()
+	}
+
+	return c.power()
+}
+
+func (c *exprCompiler) power() (exprNode, error) {
+	base, err := c.primary()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.peek() != "^" {
+		return base, nil
+	}
+
+	c.position++
+
+	// Right associative, and binds tighter than a unary minus on its left:
+	// -2^2 is -4
+	exponent, err := c.unary()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(env *exprEnv) float64 { return math.Pow(base(env), exponent(env)) }, nil
+}
+
+func (c *exprCompiler) primary() (exprNode, error) {
+	token := c.peek()
+	if token == "" {
+		return nil, fmt.Errorf("unexpected end of expression: %w", ErrInvalidExpression)
+	}
+
+	c.position++
+
+	first := []rune(token)[0]
+	switch {
+	case token == "(":
+		node, err := c.comparison()
+		if err != nil {
+			return nil, err
+		}
+
+		return node, c.expect(")")
+	case unicode.IsDigit(first) || first == '.':
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number '%s': %w", token, ErrInvalidExpression)
+		}
+
+		return func(*exprEnv) float64 { return value }, nil
+	case unicode.IsLetter(first) || first == '_':
+		if c.peek() == "(" {
+			return c.call(token)
+		}
+
+		return c.identifier(token)
+	}
+
+	return nil, fmt.Errorf("unexpected '%s': %w", token, ErrInvalidExpression)
+}
+
+func (c *exprCompiler) identifier(name string) (exprNode, error) {
+	if len(name) == 1 && name[0] >= 'a' && name[0] < 'a'+exprVariables {
+		index := int(name[0] - 'a')
+		c.used[index] = true
+
+		return func(env *exprEnv) float64 { return env.vars[index] }, nil
+	}
+
+	switch name {
+	case "sr":
+		return func(env *exprEnv) float64 { return env.sr }, nil
+	case "t":
+		return func(env *exprEnv) float64 { return env.t }, nil
+	}
+
+	if value, ok := exprConstants[name]; ok {
+		return func(*exprEnv) float64 { return value }, nil
+	}
+
+	return nil, fmt.Errorf("unknown variable '%s': %w", name, ErrInvalidExpression)
+}
+
+func (c *exprCompiler) call(name string) (exprNode, error) {
+	function, ok := exprFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function '%s': %w", name, ErrInvalidExpression)
+	}
+
+	// Skips the opening parenthesis
+	c.position++
+
+	var args []exprNode
+	if c.peek() != ")" {
+		for {
+			arg, err := c.comparison()
+			if err != nil {
+				return nil, err
+			}
+
+			args = append(args, arg)
+
+			if c.peek() != "," {
+				break
+			}
+
+			c.position++
+		}
+	}
+
+	if err := c.expect(")"); err != nil {
+		return nil, err
+	}
+
+	if len(args) != function.arity {
+		return nil, fmt.Errorf("%s takes %d arguments, got %d: %w", name, function.arity, len(args), ErrInvalidExpression)
+	}
+
+	return function.build(args), nil
+}
@@ -0,0 +1,61 @@
+package components
+
+import (
+	"math"
+	"math/bits"
+)
+
+// fft computes in place the discrete Fourier transform of x, whose length must
+// be a power of two. The inverse transform is scaled by 1/len(x), so that
+// applying both gives back the original signal.
+func fft(x []complex128, inverse bool) {
+	n := len(x)
+	if n <= 1 {
+		return
+	}
+
+	// 1. Bit reversal permutation
+	shift := 64 - uint(bits.TrailingZeros(uint(n)))
+	for i := 0; i < n; i++ {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+
+	// 2. Butterflies, doubling the size of the transforms at each pass
+	for size := 2; size <= n; size <<= 1 {
+		angle := sign * 2 * math.Pi / float64(size)
+		step := complex(math.Cos(angle), math.Sin(angle))
+
+		for start := 0; start < n; start += size {
+			twiddle := complex(1, 0)
+
+			for k := 0; k < size/2; k++ {
+				even := x[start+k]
+				odd := x[start+k+size/2] * twiddle
+
+				x[start+k] = even + odd
+				x[start+k+size/2] = even - odd
+
+				twiddle *= step
+			}
+		}
+	}
+
+	if inverse {
+		scale := complex(1/float64(n), 0)
+		for i := range x {
+			x[i] *= scale
+		}
+	}
+}
+
+func isPowerOfTwo(n int) bool {
+	return n > 0 && n&(n-1) == 0
+}
@@ -0,0 +1,40 @@
+package components
+
+import (
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+const (
+	// Delay, in seconds, around which the flanger sweeps
+	flangerCenter = 0.0035
+	// Largest distance, in seconds, of the delay from the center
+	flangerSwing = 0.003
+)
+
+// Flanger mixes the signal with a copy delayed by a swept 0.5 to 6.5 ms, whose
+// comb filter notches move along the spectrum.
+type Flanger struct {
+	description audiograph.ComponentDescription
+	delay       modulatedDelay
+}
+
+func NewFlanger() *Flanger {
+	return &Flanger{
+		description: modulationDescription("flanger", 0.25, 0.5),
+	}
+}
+
+func (f *Flanger) GetDescription() *audiograph.ComponentDescription {
+	return &f.description
+}
+
+// Prepare allocates the delay lines for the sampling frequency of the graph.
+func (f *Flanger) Prepare(samplingFrequency uint32) {
+	f.delay.prepare(samplingFrequency, flangerCenter, flangerSwing)
+}
+
+func (f *Flanger) Execute(ctx audiograph.ExecutionContext) error {
+	f.delay.process(&f.description, ctx, flangerCenter, flangerSwing)
+
+	return nil
+}
@@ -38,15 +38,10 @@ func (s *FloatToSample) GetDescription() *audiograph.ComponentDescription {
 func (s *FloatToSample) Execute(ctx audiograph.ExecutionContext) error {
 	value := s.description.Inputs[0].Value.Float
 
-	// Quick clamping
-	if value > 1.0 {
-		value = 1.0
-	} else if value < -1.0 {
-		value = -1.0
-	}
-
-	s.description.Outputs[0].Value.Sample.Left = audiograph.SampleType(value * 32767)
-	s.description.Outputs[0].Value.Sample.Right = audiograph.SampleType(value * 32767)
+	// No clamping here: float output formats keep the headroom, integer ones
+	// clamp when encoding
+	s.description.Outputs[0].Value.Sample.Left = audiograph.SampleType(value)
+	s.description.Outputs[0].Value.Sample.Right = audiograph.SampleType(value)
 
 	return nil
 }
@@ -3,6 +3,7 @@ package components
 import (
 	"fmt"
 	"github.com/sywesk/audiomix/pkg/audiograph"
+	"sort"
 )
 
 var (
@@ -11,9 +12,51 @@ var (
 
 var (
 	componentConstructorRegistry = map[string]func() audiograph.Component{
-		"FloatParam":    func() audiograph.Component { return NewFloatParam() },
-		"FloatToSample": func() audiograph.Component { return NewFloatToSample() },
-		"SinGenerator":  func() audiograph.Component { return NewSinGenerator() },
+		"ADSR":                func() audiograph.Component { return NewADSR() },
+		"Abs":                 func() audiograph.Component { return NewAbs() },
+		"Add":                 func() audiograph.Component { return NewAdd() },
+		"Biquad":              func() audiograph.Component { return NewBiquad() },
+		"Bitcrusher":          func() audiograph.Component { return NewBitcrusher() },
+		"BoolParam":           func() audiograph.Component { return NewBoolParam() },
+		"BrownNoise":          func() audiograph.Component { return NewBrownNoise() },
+		"Chorus":              func() audiograph.Component { return NewChorus() },
+		"Clamp":               func() audiograph.Component { return NewClamp() },
+		"Compressor":          func() audiograph.Component { return NewCompressor() },
+		"Convolver":           func() audiograph.Component { return NewConvolver() },
+		"Crossfade":           func() audiograph.Component { return NewCrossfade() },
+		"Delay":               func() audiograph.Component { return NewDelay() },
+		"Divide":              func() audiograph.Component { return NewDivide() },
+		"Expander":            func() audiograph.Component { return NewExpander() },
+		"Expr":                func() audiograph.Component { return NewExpr() },
+		"Flanger":             func() audiograph.Component { return NewFlanger() },
+		"FloatParam":          func() audiograph.Component { return NewFloatParam() },
+		"FloatToSample":       func() audiograph.Component { return NewFloatToSample() },
+		"Invert":              func() audiograph.Component { return NewInvert() },
+		"LadderFilter":        func() audiograph.Component { return NewLadderFilter() },
+		"Limiter":             func() audiograph.Component { return NewLimiter() },
+		"Max":                 func() audiograph.Component { return NewMax() },
+		"Min":                 func() audiograph.Component { return NewMin() },
+		"Mixer":               func() audiograph.Component { return NewMixer() },
+		"Multiply":            func() audiograph.Component { return NewMultiply() },
+		"NoiseGate":           func() audiograph.Component { return NewNoiseGate() },
+		"Pan":                 func() audiograph.Component { return NewPan() },
+		"Phaser":              func() audiograph.Component { return NewPhaser() },
+		"PinkNoise":           func() audiograph.Component { return NewPinkNoise() },
+		"PulseGenerator":      func() audiograph.Component { return NewPulseGenerator() },
+		"Reverb":              func() audiograph.Component { return NewReverb() },
+		"SVFilter":            func() audiograph.Component { return NewSVFilter() },
+		"SamplePlayer":        func() audiograph.Component { return NewSamplePlayer() },
+		"SawGenerator":        func() audiograph.Component { return NewSawGenerator() },
+		"Scale":               func() audiograph.Component { return NewScale() },
+		"SinGenerator":        func() audiograph.Component { return NewSinGenerator() },
+		"SquareGenerator":     func() audiograph.Component { return NewSquareGenerator() },
+		"StereoToSample":      func() audiograph.Component { return NewStereoToSample() },
+		"Subtract":            func() audiograph.Component { return NewSubtract() },
+		"Threshold":           func() audiograph.Component { return NewThreshold() },
+		"TriangleGenerator":   func() audiograph.Component { return NewTriangleGenerator() },
+		"Waveshaper":          func() audiograph.Component { return NewWaveshaper() },
+		"WavetableOscillator": func() audiograph.Component { return NewWavetableOscillator() },
+		"WhiteNoise":          func() audiograph.Component { return NewWhiteNoise() },
 	}
 )
 
@@ -25,3 +68,14 @@ func Instanciate(componentName string) (audiograph.Component, error) {
 
 	return constructor(), nil
 }
+
+// List returns the names of every component Instanciate knows, sorted.
+func List() []string {
+	names := make([]string, 0, len(componentConstructorRegistry))
+	for name := range componentConstructorRegistry {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
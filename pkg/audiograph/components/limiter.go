@@ -0,0 +1,183 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// Limiter keeps the signal below its threshold. It delays the signal by its
+// lookahead time so that the gain is already reduced when a peak comes, and
+// estimates the peaks happening between samples so that the signal stays below
+// the threshold once converted to analog.
+//
+// The gain needed by each sample is held for the lookahead time plus the two
+// samples covered by the peak estimation, then averaged over the lookahead
+// time. Delaying the signal by the lookahead time plus one sample, the averaged
+// gain of a sample is never above the gain it needs.
+type Limiter struct {
+	description audiograph.ComponentDescription
+
+	samplingFrequency uint32
+	lookahead         int
+
+	signal *delayLine
+	// detected holds the last samples of the detected signal, for the peak
+	// estimation
+	detected [4]float64
+	// gains holds the needed gains, which are held over its length
+	gains         []float64
+	gainsPosition int
+	// averaged holds the held gains, averaged over its length
+	averaged         []float64
+	averagedPosition int
+	averagedSum      float64
+
+	released    float64
+	releaseTime float64
+	releaseCoef float64
+}
+
+func NewLimiter() *Limiter {
+	return &Limiter{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				externalSidechainParameter(),
+				{
+					Name:        "lookahead",
+					Description: "time the signal is delayed by to reduce the gain ahead of the peaks, in seconds. defaults to 0.005",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 0.005,
+					},
+				},
+			},
+			Inputs: []audiograph.ComponentInput{
+				floatInput("in", "signal to limit", 0),
+				floatInput("sidechain", "signal whose level drives the limiting, when external_sidechain is true", 0),
+				floatInput("threshold", "level the signal is kept below, in dB", -1),
+				floatInput("release", "time for the limiting to recover, in seconds", 0.1),
+				floatInput("makeup", "gain applied before the limiting, in dB", 0),
+			},
+			Outputs: dynamicsOutputs(),
+		},
+	}
+}
+
+func (l *Limiter) GetDescription() *audiograph.ComponentDescription {
+	return &l.description
+}
+
+func (l *Limiter) OnParameterChange(name string) error {
+	if name == "lookahead" {
+		// The buffers are sized again by Prepare
+		l.samplingFrequency = 0
+	}
+
+	return nil
+}
+
+func (l *Limiter) Execute(ctx audiograph.ExecutionContext) error {
+	makeup := fromDecibels(l.description.Inputs[4].Value.Float)
+	in := l.description.Inputs[0].Value.Float * makeup
+	ceiling := fromDecibels(l.description.Inputs[2].Value.Float)
+	release := l.description.Inputs[3].Value.Float
+
+	detected := in
+	if l.description.Parameters[0].Value.Bool {
+		detected = l.description.Inputs[1].Value.Float * makeup
+	}
+
+	// Only happens when the component is used outside of a graph, which
+	// otherwise calls Prepare beforehand
+	l.Prepare(ctx.SamplingFrequency)
+
+	if release != l.releaseTime {
+		l.releaseTime = release
+		l.releaseCoef = timeCoefficient(release, ctx.SamplingFrequency)
+	}
+
+	// 1. Peak of the last sample, and between the two samples before it
+	copy(l.detected[:], l.detected[1:])
+	l.detected[3] = detected
+
+	peak := math.Abs(detected)
+	for _, frac := range []float64{0.25, 0.5, 0.75} {
+		peak = math.Max(peak, math.Abs(hermite(l.detected[0], l.detected[1], l.detected[2], l.detected[3], frac)))
+	}
+
+	needed := 1.0
+	if peak > ceiling {
+		needed = ceiling / peak
+	}
+
+	// 2. Hold
+	l.gains[l.gainsPosition] = needed
+	l.gainsPosition = (l.gainsPosition + 1) % len(l.gains)
+
+	held := 1.0
+	for _, gain := range l.gains {
+		held = math.Min(held, gain)
+	}
+
+	// 3. Release, which can only make the gain go up slower
+	if held < l.released {
+		l.released = held
+	} else {
+		l.released = held + (l.released-held)*l.releaseCoef
+	}
+
+	// 4. Average, the sum being computed again once in a while so that rounding
+	// errors do not accumulate
+	l.averagedSum += l.released - l.averaged[l.averagedPosition]
+	l.averaged[l.averagedPosition] = l.released
+	l.averagedPosition++
+	if l.averagedPosition == len(l.averaged) {
+		l.averagedPosition = 0
+
+		l.averagedSum = 0
+		for _, gain := range l.averaged {
+			l.averagedSum += gain
+		}
+	}
+
+	gain := l.averagedSum / float64(len(l.averaged))
+
+	delayed := l.signal.at(l.lookahead + 1)
+	l.signal.write(in)
+
+	l.description.Outputs[0].Value.Float = delayed * gain
+	l.description.Outputs[1].Value.Float = -toDecibels(gain)
+
+	return nil
+}
+
+// Prepare sizes the buffers for the lookahead time at the sampling frequency
+// of the graph.
+func (l *Limiter) Prepare(samplingFrequency uint32) {
+	if samplingFrequency == l.samplingFrequency {
+		return
+	}
+
+	lookahead := math.Max(l.description.Parameters[1].Value.Float, 0)
+
+	l.samplingFrequency = samplingFrequency
+	l.lookahead = int(math.Max(1, math.Round(lookahead*float64(samplingFrequency))))
+
+	l.signal = newDelayLine(l.lookahead + 1)
+	l.detected = [4]float64{}
+	l.gains = make([]float64, l.lookahead+2)
+	l.averaged = make([]float64, l.lookahead)
+	l.released = 1
+	l.releaseTime = -1
+
+	for i := range l.gains {
+		l.gains[i] = 1
+	}
+	for i := range l.averaged {
+		l.averaged[i] = 1
+	}
+	l.averagedSum = float64(l.lookahead)
+	l.gainsPosition = 0
+	l.averagedPosition = 0
+}
@@ -0,0 +1,143 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+var (
+	ErrInvalidMixerInputs = fmt.Errorf("invalid mixer inputs")
+)
+
+const (
+	defaultMixerInputs = 4
+	// mixerChannelPorts is the number of inputs of each channel: in, left,
+	// right, gain and mute
+	mixerChannelPorts = 5
+)
+
+// Mixer sums any number of channels, each one having its own gain and mute.
+// A channel takes either a mono signal on its in input, or a stereo one on its
+// left and right inputs. Mono sources can also be placed in the stereo field
+// with a Pan before being mixed.
+type Mixer struct {
+	description audiograph.ComponentDescription
+}
+
+func NewMixer() *Mixer {
+	mixer := &Mixer{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				{
+					Name:        "inputs",
+					Description: fmt.Sprintf("number of channels. min 1, defaults to %d", defaultMixerInputs),
+					Value: audiograph.Value{
+						Type:    audiograph.IntegerValueType,
+						Integer: defaultMixerInputs,
+					},
+				},
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "left",
+					Description: "sum of the left channels",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "right",
+					Description: "sum of the right channels",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "out",
+					Description: "mono mix, the average of left and right. the sum of the channels when they are all mono",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+	}
+
+	mixer.buildInputs(defaultMixerInputs)
+
+	return mixer
+}
+
+// buildInputs declares the master gain, followed by the inputs of each
+// channel.
+func (m *Mixer) buildInputs(channels int) {
+	inputs := []audiograph.ComponentInput{
+		floatInput("master", "gain applied to the whole mix. defaults to 1", 1),
+	}
+
+	for i := 1; i <= channels; i++ {
+		inputs = append(inputs,
+			floatInput(fmt.Sprintf("in%d", i), fmt.Sprintf("mono signal of channel %d, sent to both sides", i), 0),
+			floatInput(fmt.Sprintf("left%d", i), fmt.Sprintf("left side of channel %d", i), 0),
+			floatInput(fmt.Sprintf("right%d", i), fmt.Sprintf("right side of channel %d", i), 0),
+			floatInput(fmt.Sprintf("gain%d", i), fmt.Sprintf("gain applied to channel %d. defaults to 1", i), 1),
+			audiograph.ComponentInput{
+				Name:        fmt.Sprintf("mute%d", i),
+				Description: fmt.Sprintf("when true, channel %d is left out of the mix", i),
+				Value: audiograph.Value{
+					Type: audiograph.BoolValueType,
+				},
+			},
+		)
+	}
+
+	m.description.Inputs = inputs
+}
+
+func (m *Mixer) GetDescription() *audiograph.ComponentDescription {
+	return &m.description
+}
+
+func (m *Mixer) OnParameterChange(name string) error {
+	if name != "inputs" {
+		return nil
+	}
+
+	channels := m.description.Parameters[0].Value.Integer
+	if channels < 1 {
+		return fmt.Errorf("%d channels: %w", channels, ErrInvalidMixerInputs)
+	}
+
+	m.buildInputs(int(channels))
+
+	return nil
+}
+
+func (m *Mixer) Execute(ctx audiograph.ExecutionContext) error {
+	left := 0.0
+	right := 0.0
+
+	inputs := m.description.Inputs
+	for i := 1; i < len(inputs); i += mixerChannelPorts {
+		if inputs[i+4].Value.Bool {
+			continue
+		}
+
+		mono := inputs[i].Value.Float
+		gain := inputs[i+3].Value.Float
+
+		left += (mono + inputs[i+1].Value.Float) * gain
+		right += (mono + inputs[i+2].Value.Float) * gain
+	}
+
+	master := inputs[0].Value.Float
+	left *= master
+	right *= master
+
+	m.description.Outputs[0].Value.Float = left
+	m.description.Outputs[1].Value.Float = right
+	m.description.Outputs[2].Value.Float = (left + right) / 2
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// modulator generates the modulation of the time-modulated effects: a sine
+// LFO per channel, the right one being late by half a period times the spread,
+// or the external mod input.
+//
+// Indexes of the inputs shared by these effects are: 0 left, 1 right, 2 rate,
+// 3 depth, 4 feedback, 5 spread, 6 mix, 7 mod. Parameter 0 is external_mod.
+type modulator struct {
+	phase float64
+}
+
+// modulationDescription returns the description of a time-modulated effect,
+// with the given defaults for the feedback and rate inputs.
+func modulationDescription(effect string, rate, feedback float64) audiograph.ComponentDescription {
+	return audiograph.ComponentDescription{
+		Parameters: []audiograph.ComponentParameter{
+			{
+				Name:        "external_mod",
+				Description: "when true, the mod input drives the " + effect + " instead of the internal LFO",
+				Value: audiograph.Value{
+					Type: audiograph.BoolValueType,
+				},
+			},
+		},
+		Inputs: []audiograph.ComponentInput{
+			floatInput("left", "left channel", 0),
+			floatInput("right", "right channel", 0),
+			floatInput("rate", "frequency of the internal LFO, in Hz", rate),
+			floatInput("depth", "amount of modulation. from 0 to 1", 0.5),
+			floatInput("feedback", "amount of the output sent back into the "+effect+". from -1 to 1", feedback),
+			floatInput("spread", "phase offset of the modulation of the right channel. from 0 to 1, 1 being half a period", 0.5),
+			floatInput("mix", "balance between the dry (0) and the processed (1) signals", 0.5),
+			floatInput("mod", "modulation used instead of the internal LFO when external_mod is true. from -1 to 1", 0),
+		},
+		Outputs: []audiograph.ComponentOutput{
+			{
+				Name:        "left",
+				Description: "left channel",
+				Value: audiograph.Value{
+					Type: audiograph.FloatValueType,
+				},
+			},
+			{
+				Name:        "right",
+				Description: "right channel",
+				Value: audiograph.Value{
+					Type: audiograph.FloatValueType,
+				},
+			},
+		},
+	}
+}
+
+// next returns the modulation of the left and right channels, between -1 and
+// 1. With an external modulation, a full spread inverts the right channel.
+func (m *modulator) next(description *audiograph.ComponentDescription, samplingFrequency uint32) (float64, float64) {
+	spread := clamp(description.Inputs[5].Value.Float, 0, 1)
+
+	if description.Parameters[0].Value.Bool {
+		mod := clamp(description.Inputs[7].Value.Float, -1, 1)
+		return mod, mod * (1 - 2*spread)
+	}
+
+	left := math.Sin(2 * math.Pi * m.phase)
+	right := math.Sin(2 * math.Pi * (m.phase - spread/2))
+
+	m.phase = wrapPhase(m.phase + description.Inputs[2].Value.Float/float64(samplingFrequency))
+
+	return left, right
+}
+
+// modulatedDelay is the stereo delay swept by the modulator, shared by the
+// chorus and the flanger.
+type modulatedDelay struct {
+	modulator         modulator
+	samplingFrequency uint32
+
+	leftLine, rightLine *delayLine
+	leftTap, rightTap   delayTap
+}
+
+// prepare allocates the delay lines, long enough for a delay of center plus
+// swing seconds at the given sampling frequency.
+func (m *modulatedDelay) prepare(samplingFrequency uint32, center, swing float64) {
+	if m.leftLine != nil && samplingFrequency == m.samplingFrequency {
+		return
+	}
+
+	size := int(math.Ceil((center + swing) * float64(samplingFrequency)))
+
+	m.samplingFrequency = samplingFrequency
+	m.leftLine = newDelayLine(size)
+	m.rightLine = newDelayLine(size)
+	m.leftTap = delayTap{interpolation: cubicInterpolation}
+	m.rightTap = delayTap{interpolation: cubicInterpolation}
+}
+
+// process runs one sample of the effect described by description, whose delay
+// swings by up to swing seconds around center seconds.
+func (m *modulatedDelay) process(description *audiograph.ComponentDescription, ctx audiograph.ExecutionContext, center, swing float64) {
+	left := description.Inputs[0].Value.Float
+	right := description.Inputs[1].Value.Float
+	depth := clamp(description.Inputs[3].Value.Float, 0, 1)
+	feedback := clamp(description.Inputs[4].Value.Float, -0.95, 0.95)
+	mix := clamp(description.Inputs[6].Value.Float, 0, 1)
+
+	// Only happens when the effect is used outside of a graph, which otherwise
+	// calls Prepare beforehand
+	m.prepare(ctx.SamplingFrequency, center, swing)
+
+	leftMod, rightMod := m.modulator.next(description, ctx.SamplingFrequency)
+	samples := float64(ctx.SamplingFrequency)
+
+	wetLeft := m.leftTap.read(m.leftLine, (center+leftMod*depth*swing)*samples)
+	wetRight := m.rightTap.read(m.rightLine, (center+rightMod*depth*swing)*samples)
+
+	m.leftLine.write(left + wetLeft*feedback)
+	m.rightLine.write(right + wetRight*feedback)
+
+	description.Outputs[0].Value.Float = left*(1-mix) + wetLeft*mix
+	description.Outputs[1].Value.Float = right*(1-mix) + wetRight*mix
+}
@@ -0,0 +1,98 @@
+package components
+
+import (
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// noiseSource is a xorshift64* generator. It is much faster than math/rand,
+// and its output for a given seed will never change, which keeps renders
+// byte-identical across versions.
+type noiseSource struct {
+	state uint64
+	// explicitSeed is set once the seed parameter is used, the seed of the graph
+	// is then ignored.
+	explicitSeed bool
+}
+
+// noiseDescription returns the description shared by the noise generators.
+// Indexes are: parameter 0 seed, inputs 0 gain, 1 offset, output 0 noise.
+func noiseDescription(color string) audiograph.ComponentDescription {
+	return audiograph.ComponentDescription{
+		Parameters: []audiograph.ComponentParameter{
+			{
+				Name:        "seed",
+				Description: "seed of the random generator. defaults to a seed derived from the seed of the graph",
+				Value: audiograph.Value{
+					Type: audiograph.IntegerValueType,
+				},
+			},
+		},
+		Inputs: []audiograph.ComponentInput{
+			{
+				Name:        "gain",
+				Description: "controls the amplitude of the generated " + color + " noise. min 0",
+				Value: audiograph.Value{
+					Type: audiograph.FloatValueType,
+				},
+			},
+			{
+				Name:        "offset",
+				Description: "controls the offset of the generated " + color + " noise.",
+				Value: audiograph.Value{
+					Type: audiograph.FloatValueType,
+				},
+			},
+		},
+		Outputs: []audiograph.ComponentOutput{
+			{
+				Name:        "noise",
+				Description: color + " noise",
+				Value: audiograph.Value{
+					Type: audiograph.FloatValueType,
+				},
+			},
+		},
+	}
+}
+
+func newNoiseSource() noiseSource {
+	source := noiseSource{}
+	source.reset(0)
+
+	return source
+}
+
+// seedFromGraph applies the seed given by the graph, unless the seed parameter
+// was set.
+func (n *noiseSource) seedFromGraph(seed int64) {
+	if !n.explicitSeed {
+		n.reset(seed)
+	}
+}
+
+// seedFromParameter applies the seed parameter, which takes precedence over
+// the seed of the graph.
+func (n *noiseSource) seedFromParameter(seed int64) {
+	n.explicitSeed = true
+	n.reset(seed)
+}
+
+func (n *noiseSource) reset(seed int64) {
+	// xorshift generators must not start from 0
+	n.state = uint64(seed) ^ 0x2545F4914F6CDD1D
+	if n.state == 0 {
+		n.state = 0x2545F4914F6CDD1D
+	}
+}
+
+// next returns a uniformly distributed number between -1 and 1.
+func (n *noiseSource) next() float64 {
+	n.state ^= n.state >> 12
+	n.state ^= n.state << 25
+	n.state ^= n.state >> 27
+
+	value := n.state * 0x2545F4914F6CDD1D
+
+	// Use the 53 high bits, as many as a float64 mantissa holds
+	return float64(value>>11)/(1<<52) - 1
+}
@@ -0,0 +1,76 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// NoiseGate mutes the signal while its level stays below the threshold. Once
+// open, the gate is held open for a while so that it does not close on every
+// zero crossing of low frequencies.
+type NoiseGate struct {
+	description audiograph.ComponentDescription
+	smoother    gainSmoother
+
+	// holdSamples is the number of samples the gate stays open
+	holdSamples float64
+}
+
+func NewNoiseGate() *NoiseGate {
+	return &NoiseGate{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				externalSidechainParameter(),
+			},
+			Inputs: []audiograph.ComponentInput{
+				floatInput("in", "signal to gate", 0),
+				floatInput("sidechain", "signal whose level opens the gate, when external_sidechain is true", 0),
+				floatInput("threshold", "level above which the gate opens, in dB", -50),
+				floatInput("range", "attenuation of the signal when the gate is closed, in dB. min 0", 80),
+				floatInput("attack", "time for the gate to open, in seconds", 0.001),
+				floatInput("hold", "time the gate stays open once the level is below the threshold, in seconds", 0.05),
+				floatInput("release", "time for the gate to close, in seconds", 0.1),
+			},
+			Outputs: dynamicsOutputs(),
+		},
+	}
+}
+
+func (n *NoiseGate) GetDescription() *audiograph.ComponentDescription {
+	return &n.description
+}
+
+func (n *NoiseGate) Execute(ctx audiograph.ExecutionContext) error {
+	in := n.description.Inputs[0].Value.Float
+	threshold := n.description.Inputs[2].Value.Float
+	attenuation := math.Max(n.description.Inputs[3].Value.Float, 0)
+	attack := n.description.Inputs[4].Value.Float
+	hold := n.description.Inputs[5].Value.Float
+	release := n.description.Inputs[6].Value.Float
+
+	detected := in
+	if n.description.Parameters[0].Value.Bool {
+		detected = n.description.Inputs[1].Value.Float
+	}
+
+	if toDecibels(detected) > threshold {
+		n.holdSamples = math.Max(hold*float64(ctx.SamplingFrequency), 1)
+	} else if n.holdSamples > 0 {
+		n.holdSamples--
+	}
+
+	target := attenuation
+	if n.holdSamples > 0 {
+		target = 0
+	}
+
+	// The gain reduction grows when the gate closes, so the release of the gate
+	// is the attack of the smoother
+	reduction := n.smoother.smooth(target, release, attack, ctx.SamplingFrequency)
+
+	n.description.Outputs[0].Value.Float = in * fromDecibels(-reduction)
+	n.description.Outputs[1].Value.Float = reduction
+
+	return nil
+}
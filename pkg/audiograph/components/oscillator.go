@@ -0,0 +1,115 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// oscillator holds the state shared by the band-limited generators: a phase
+// going from 0 to 1 over a period, which restarts on rising edges of the sync
+// input for hard sync.
+type oscillator struct {
+	phase    float64
+	lastSync float64
+}
+
+// oscillatorInputs returns the inputs shared by the band-limited generators,
+// following the conventions of SinGenerator. Indexes are: 0 freq, 1 gain,
+// 2 offset, 3 sync.
+func oscillatorInputs(waveform string) []audiograph.ComponentInput {
+	return []audiograph.ComponentInput{
+		{
+			Name:        "freq",
+			Description: "frequency of the generated " + waveform + ". between 0 and sampling frequency",
+			Value: audiograph.Value{
+				Type: audiograph.FloatValueType,
+			},
+		},
+		{
+			Name:        "gain",
+			Description: "controls the amplitude of the generated " + waveform + ". min 0",
+			Value: audiograph.Value{
+				Type: audiograph.FloatValueType,
+			},
+		},
+		{
+			Name:        "offset",
+			Description: "controls the offset of the generated " + waveform + ".",
+			Value: audiograph.Value{
+				Type: audiograph.FloatValueType,
+			},
+		},
+		{
+			Name:        "sync",
+			Description: "restarts the period when going from negative or zero to positive, for hard sync",
+			Value: audiograph.Value{
+				Type: audiograph.FloatValueType,
+			},
+		},
+	}
+}
+
+// advance returns the phase to render for the current sample along with the
+// phase increment per sample, then moves the oscillator forward.
+func (o *oscillator) advance(freq float64, sync float64, samplingFrequency uint32) (float64, float64) {
+	if sync > 0 && o.lastSync <= 0 {
+		o.phase = 0
+	}
+	o.lastSync = sync
+
+	phase := o.phase
+	increment := freq / float64(samplingFrequency)
+
+	o.phase += increment
+	o.phase -= math.Floor(o.phase)
+
+	// PolyBLEP corrections spread over one sample on each side of a
+	// discontinuity, they cannot handle more than one per half period.
+	increment = math.Min(math.Abs(increment), 0.5)
+
+	return phase, increment
+}
+
+// polyBLEP returns the correction to apply to a naive waveform around a
+// discontinuity of height 2 located at phase 0, dt being the phase increment
+// per sample.
+func polyBLEP(t float64, dt float64) float64 {
+	if dt == 0 {
+		return 0
+	}
+
+	if t < dt {
+		t /= dt
+		return t + t - t*t - 1
+	} else if t > 1-dt {
+		t = (t - 1) / dt
+		return t*t + t + t + 1
+	}
+
+	return 0
+}
+
+// polyBLAMP returns the correction to apply to a naive waveform around a
+// change of slope located at phase 0. It must be scaled by half the slope
+// change per sample.
+func polyBLAMP(t float64, dt float64) float64 {
+	if dt == 0 {
+		return 0
+	}
+
+	if t < dt {
+		t = t/dt - 1
+		return -t * t * t / 3
+	} else if t > 1-dt {
+		t = (t-1)/dt + 1
+		return t * t * t / 3
+	}
+
+	return 0
+}
+
+// wrapPhase brings a phase back between 0 and 1.
+func wrapPhase(phase float64) float64 {
+	return phase - math.Floor(phase)
+}
@@ -0,0 +1,77 @@
+package components
+
+import "math"
+
+const (
+	// oversamplerTaps is the number of taps of the lowpass filters for each
+	// sample at the original rate.
+	oversamplerTaps = 12
+	// oversamplerCutoff is the cutoff of the lowpass filters, relative to the
+	// Nyquist frequency of the original rate. Just below 1 so that the
+	// transition band mostly lies above it.
+	oversamplerCutoff = 0.9
+)
+
+// oversampler runs a nonlinear process at a multiple of the sampling rate, so
+// that the harmonics it adds above the original Nyquist frequency are filtered
+// out instead of aliasing back into the audible range.
+type oversampler struct {
+	factor int
+	// kernel is a Blackman windowed sinc lowpass, used both to interpolate the
+	// upsampled signal and to filter it before decimation
+	kernel []float64
+
+	input     *delayLine
+	upsampled *delayLine
+}
+
+func newOversampler(factor int) *oversampler {
+	length := oversamplerTaps * factor
+	cutoff := oversamplerCutoff / float64(factor)
+	center := float64(length-1) / 2
+
+	kernel := make([]float64, length)
+	for i := range kernel {
+		x := float64(i) - center
+		phase := 2 * math.Pi * float64(i) / float64(length-1)
+		window := 0.42 - 0.5*math.Cos(phase) + 0.08*math.Cos(2*phase)
+
+		kernel[i] = cutoff * sinc(x*cutoff) * window
+	}
+
+	return &oversampler{
+		factor:    factor,
+		kernel:    kernel,
+		input:     newDelayLine(oversamplerTaps),
+		upsampled: newDelayLine(length),
+	}
+}
+
+// process upsamples in, applies fn to every upsampled value and returns the
+// result decimated back to the original rate.
+func (o *oversampler) process(in float64, fn func(float64) float64) float64 {
+	if o.factor <= 1 {
+		return fn(in)
+	}
+
+	o.input.write(in)
+
+	// Polyphase interpolation: the zeros inserted between the input samples
+	// are skipped, each phase only using the taps that land on an input sample
+	for phase := 0; phase < o.factor; phase++ {
+		sum := 0.0
+		for tap := 0; tap < oversamplerTaps; tap++ {
+			sum += o.kernel[tap*o.factor+phase] * o.input.at(tap+1)
+		}
+
+		o.upsampled.write(fn(sum * float64(o.factor)))
+	}
+
+	// Only the decimated samples are filtered
+	out := 0.0
+	for i, coef := range o.kernel {
+		out += coef * o.upsampled.at(i+1)
+	}
+
+	return out
+}
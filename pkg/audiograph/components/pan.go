@@ -0,0 +1,122 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+var (
+	ErrUnknownPanLaw = fmt.Errorf("unknown pan law")
+)
+
+// A panLaw returns the gains of the left and right channels for a position
+// going from 0 (hard left) to 1 (hard right).
+type panLaw func(position float64) (float64, float64)
+
+var (
+	panLaws = map[string]panLaw{
+		// -6 dB at the center
+		"linear": func(position float64) (float64, float64) {
+			return 1 - position, position
+		},
+		// -3 dB at the center, the perceived loudness stays constant
+		"constant-power": func(position float64) (float64, float64) {
+			return math.Cos(position * math.Pi / 2), math.Sin(position * math.Pi / 2)
+		},
+		// -4.5 dB at the center, a compromise between the two above
+		"-4.5db": func(position float64) (float64, float64) {
+			return math.Sqrt((1 - position) * math.Cos(position*math.Pi/2)),
+				math.Sqrt(position * math.Sin(position*math.Pi/2))
+		},
+	}
+)
+
+type Pan struct {
+	description audiograph.ComponentDescription
+	law         panLaw
+}
+
+func NewPan() *Pan {
+	return &Pan{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				{
+					Name:        "law",
+					Description: "pan law: linear, constant-power (default) or -4.5dB",
+					Value: audiograph.Value{
+						Type:   audiograph.StringValueType,
+						String: "constant-power",
+					},
+				},
+			},
+			Inputs: []audiograph.ComponentInput{
+				{
+					Name:        "in",
+					Description: "mono signal to place in the stereo field",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "pan",
+					Description: "position of the signal. from -1 (left) to 1 (right), 0 is the center",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "left",
+					Description: "left channel",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "right",
+					Description: "right channel",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+		law: panLaws["constant-power"],
+	}
+}
+
+func (p *Pan) GetDescription() *audiograph.ComponentDescription {
+	return &p.description
+}
+
+func (p *Pan) OnParameterChange(name string) error {
+	if name != "law" {
+		return nil
+	}
+
+	lawName := p.description.Parameters[0].Value.String
+
+	law, ok := panLaws[strings.ToLower(lawName)]
+	if !ok {
+		return fmt.Errorf("pan law '%s': %w", lawName, ErrUnknownPanLaw)
+	}
+
+	p.law = law
+	return nil
+}
+
+func (p *Pan) Execute(ctx audiograph.ExecutionContext) error {
+	in := p.description.Inputs[0].Value.Float
+	position := (clamp(p.description.Inputs[1].Value.Float, -1.0, 1.0) + 1) / 2
+
+	leftGain, rightGain := p.law(position)
+
+	p.description.Outputs[0].Value.Float = in * leftGain
+	p.description.Outputs[1].Value.Float = in * rightGain
+
+	return nil
+}
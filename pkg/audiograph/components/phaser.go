@@ -0,0 +1,104 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+const (
+	// Range, in Hz, swept by the break frequency of the allpasses
+	phaserMinFrequency = 200.0
+	phaserMaxFrequency = 2000.0
+	// Largest number of allpasses of a channel
+	phaserMaxStages = 12
+)
+
+// phaserAllpass is a first order allpass, shifting the phase of the signal by
+// 90° at its break frequency.
+type phaserAllpass struct {
+	input, output float64
+}
+
+func (a *phaserAllpass) process(in, coef float64) float64 {
+	a.output = coef*in + a.input - coef*a.output
+	a.input = in
+
+	return a.output
+}
+
+// phaserChannel holds the serial allpasses of one channel.
+type phaserChannel struct {
+	allpasses [phaserMaxStages]phaserAllpass
+	last      float64
+}
+
+func (c *phaserChannel) process(in, coef, feedback float64, stages int) float64 {
+	out := in + c.last*feedback
+	for i := 0; i < stages; i++ {
+		out = c.allpasses[i].process(out, coef)
+	}
+
+	c.last = out
+
+	return out
+}
+
+// Phaser mixes the signal with a copy going through a chain of allpasses whose
+// break frequency is swept, moving the notches where both are out of phase.
+type Phaser struct {
+	description audiograph.ComponentDescription
+
+	modulator   modulator
+	left, right phaserChannel
+}
+
+func NewPhaser() *Phaser {
+	description := modulationDescription("phaser", 0.5, 0.3)
+	description.Parameters = append(description.Parameters, audiograph.ComponentParameter{
+		Name:        "stages",
+		Description: "number of allpasses, each pair adding a notch. from 1 to 12, defaults to 4",
+		Value: audiograph.Value{
+			Type:    audiograph.IntegerValueType,
+			Integer: 4,
+		},
+	})
+
+	return &Phaser{
+		description: description,
+	}
+}
+
+func (p *Phaser) GetDescription() *audiograph.ComponentDescription {
+	return &p.description
+}
+
+func (p *Phaser) Execute(ctx audiograph.ExecutionContext) error {
+	left := p.description.Inputs[0].Value.Float
+	right := p.description.Inputs[1].Value.Float
+	depth := clamp(p.description.Inputs[3].Value.Float, 0, 1)
+	feedback := clamp(p.description.Inputs[4].Value.Float, -0.95, 0.95)
+	mix := clamp(p.description.Inputs[6].Value.Float, 0, 1)
+	stages := int(clamp(float64(p.description.Parameters[1].Value.Integer), 1, phaserMaxStages))
+
+	leftMod, rightMod := p.modulator.next(&p.description, ctx.SamplingFrequency)
+
+	wetLeft := p.left.process(left, p.coefficient(leftMod*depth, ctx.SamplingFrequency), feedback, stages)
+	wetRight := p.right.process(right, p.coefficient(rightMod*depth, ctx.SamplingFrequency), feedback, stages)
+
+	p.description.Outputs[0].Value.Float = left*(1-mix) + wetLeft*mix
+	p.description.Outputs[1].Value.Float = right*(1-mix) + wetRight*mix
+
+	return nil
+}
+
+// coefficient returns the coefficient of the allpasses for a modulation going
+// from -1 to 1, which sweeps the break frequency exponentially.
+func (p *Phaser) coefficient(mod float64, samplingFrequency uint32) float64 {
+	frequency := phaserMinFrequency * math.Pow(phaserMaxFrequency/phaserMinFrequency, (mod+1)/2)
+	frequency = math.Min(frequency, 0.45*float64(samplingFrequency))
+
+	t := math.Tan(math.Pi * frequency / float64(samplingFrequency))
+
+	return (t - 1) / (t + 1)
+}
@@ -0,0 +1,59 @@
+package components
+
+import (
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// PinkNoise generates noise whose power decreases by 3 dB per octave, using
+// Paul Kellet's refined filter over white noise.
+type PinkNoise struct {
+	description audiograph.ComponentDescription
+	source      noiseSource
+	b           [7]float64
+}
+
+func NewPinkNoise() *PinkNoise {
+	return &PinkNoise{
+		description: noiseDescription("pink"),
+		source:      newNoiseSource(),
+	}
+}
+
+func (p *PinkNoise) GetDescription() *audiograph.ComponentDescription {
+	return &p.description
+}
+
+func (p *PinkNoise) OnParameterChange(name string) error {
+	if name == "seed" {
+		p.source.seedFromParameter(p.description.Parameters[0].Value.Integer)
+		p.b = [7]float64{}
+	}
+
+	return nil
+}
+
+func (p *PinkNoise) Seed(seed int64) {
+	p.source.seedFromGraph(seed)
+	p.b = [7]float64{}
+}
+
+func (p *PinkNoise) Execute(ctx audiograph.ExecutionContext) error {
+	gain := p.description.Inputs[0].Value.Float
+	offset := p.description.Inputs[1].Value.Float
+
+	white := p.source.next()
+
+	p.b[0] = 0.99886*p.b[0] + white*0.0555179
+	p.b[1] = 0.99332*p.b[1] + white*0.0750759
+	p.b[2] = 0.96900*p.b[2] + white*0.1538520
+	p.b[3] = 0.86650*p.b[3] + white*0.3104856
+	p.b[4] = 0.55000*p.b[4] + white*0.5329522
+	p.b[5] = -0.7616*p.b[5] - white*0.0168980
+	pink := p.b[0] + p.b[1] + p.b[2] + p.b[3] + p.b[4] + p.b[5] + p.b[6] + white*0.5362
+	p.b[6] = white * 0.115926
+
+	// Brings the output roughly between -1 and 1
+	p.description.Outputs[0].Value.Float = pink*0.11*gain + offset
+
+	return nil
+}
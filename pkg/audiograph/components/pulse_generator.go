@@ -0,0 +1,73 @@
+package components
+
+import (
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// PulseGenerator generates a pulse wave of variable width, anti-aliased with
+// PolyBLEP. Modulating the width gives pulse width modulation.
+type PulseGenerator struct {
+	description audiograph.ComponentDescription
+	oscillator  oscillator
+}
+
+func NewPulseGenerator() *PulseGenerator {
+	inputs := append(oscillatorInputs("pulse"), audiograph.ComponentInput{
+		Name:        "width",
+		Description: "part of the period spent high, between 0 and 1. defaults to 0.5, a square",
+		Value: audiograph.Value{
+			Type:  audiograph.FloatValueType,
+			Float: 0.5,
+		},
+	})
+
+	return &PulseGenerator{
+		description: audiograph.ComponentDescription{
+			Inputs: inputs,
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "pulse",
+					Description: "pulse curve",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (p *PulseGenerator) GetDescription() *audiograph.ComponentDescription {
+	return &p.description
+}
+
+func (p *PulseGenerator) Execute(ctx audiograph.ExecutionContext) error {
+	freq := p.description.Inputs[0].Value.Float
+	gain := p.description.Inputs[1].Value.Float
+	offset := p.description.Inputs[2].Value.Float
+	sync := p.description.Inputs[3].Value.Float
+	width := p.description.Inputs[4].Value.Float
+
+	phase, dt := p.oscillator.advance(freq, sync, ctx.SamplingFrequency)
+
+	// Keep both edges at least a sample apart
+	width = clamp(width, dt, 1-dt)
+
+	p.description.Outputs[0].Value.Float = pulse(phase, dt, width)*gain + offset
+
+	return nil
+}
+
+// pulse returns a band-limited pulse going from 1 to -1 at phase width, and
+// back to 1 at phase 0.
+func pulse(phase float64, dt float64, width float64) float64 {
+	value := -1.0
+	if phase < width {
+		value = 1.0
+	}
+
+	value += polyBLEP(phase, dt)
+	value -= polyBLEP(wrapPhase(phase+1-width), dt)
+
+	return value
+}
@@ -0,0 +1,53 @@
+package components
+
+import "math"
+
+const (
+	// resampleHalfTaps is the number of zero crossings of the sinc kernel on
+	// each side of the interpolated point.
+	resampleHalfTaps = 16
+)
+
+// resample converts samples recorded at fromRate to toRate using a Blackman
+// windowed sinc interpolation. When downsampling, the kernel is widened so that
+// it also filters out what would alias above the new Nyquist frequency.
+func resample(samples []float64, fromRate uint32, toRate uint32) []float64 {
+	if fromRate == toRate || fromRate == 0 || toRate == 0 || len(samples) == 0 {
+		return append([]float64(nil), samples...)
+	}
+
+	ratio := float64(fromRate) / float64(toRate)
+	cutoff := math.Min(1, 1/ratio)
+	halfWidth := float64(resampleHalfTaps) / cutoff
+
+	output := make([]float64, int(math.Ceil(float64(len(samples))/ratio)))
+
+	for i := range output {
+		center := float64(i) * ratio
+		first := int(math.Ceil(center - halfWidth))
+		last := int(math.Floor(center + halfWidth))
+
+		sum := 0.0
+		for j := first; j <= last; j++ {
+			if j < 0 || j >= len(samples) {
+				continue
+			}
+
+			x := float64(j) - center
+			window := 0.42 + 0.5*math.Cos(math.Pi*x/halfWidth) + 0.08*math.Cos(2*math.Pi*x/halfWidth)
+			sum += samples[j] * cutoff * sinc(x*cutoff) * window
+		}
+
+		output[i] = sum
+	}
+
+	return output
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
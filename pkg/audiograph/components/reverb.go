@@ -0,0 +1,245 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+const (
+	// Longest predelay, in seconds
+	reverbMaxPredelay = 0.5
+	// Offset, in samples at 44.1 kHz, between the tunings of the left and right
+	// channels, which decorrelates them
+	reverbStereoSpread = 23
+)
+
+var (
+	// Tunings of Jezar's Freeverb, in samples at 44.1 kHz
+	reverbCombTunings    = []int{1116, 1188, 1277, 1356, 1422, 1491, 1557, 1617}
+	reverbAllpassTunings = []int{556, 441, 341, 225}
+)
+
+// reverbComb is a feedback comb filter with a lowpass in its loop, which makes
+// the high frequencies decay faster.
+type reverbComb struct {
+	buffer   []float64
+	position int
+	filtered float64
+}
+
+func (c *reverbComb) process(in, feedback, damping float64) float64 {
+	out := c.buffer[c.position]
+
+	c.filtered = out*(1-damping) + c.filtered*damping
+	c.buffer[c.position] = in + c.filtered*feedback
+
+	c.position++
+	if c.position == len(c.buffer) {
+		c.position = 0
+	}
+
+	return out
+}
+
+// reverbAllpass is a Schroeder allpass, diffusing the echoes of the combs.
+type reverbAllpass struct {
+	buffer   []float64
+	position int
+}
+
+func (a *reverbAllpass) process(in float64) float64 {
+	delayed := a.buffer[a.position]
+
+	a.buffer[a.position] = in + delayed*0.5
+
+	a.position++
+	if a.position == len(a.buffer) {
+		a.position = 0
+	}
+
+	return delayed - in
+}
+
+// reverbChannel holds the parallel combs and the serial allpasses of one
+// channel.
+type reverbChannel struct {
+	combs     []reverbComb
+	allpasses []reverbAllpass
+}
+
+func newReverbChannel(samplingFrequency uint32, spread int) reverbChannel {
+	scale := float64(samplingFrequency) / 44100
+
+	size := func(tuning int) int {
+		return int(math.Max(1, math.Round(float64(tuning+spread)*scale)))
+	}
+
+	channel := reverbChannel{}
+	for _, tuning := range reverbCombTunings {
+		channel.combs = append(channel.combs, reverbComb{buffer: make([]float64, size(tuning))})
+	}
+	for _, tuning := range reverbAllpassTunings {
+		channel.allpasses = append(channel.allpasses, reverbAllpass{buffer: make([]float64, size(tuning))})
+	}
+
+	return channel
+}
+
+func (r *reverbChannel) process(in, feedback, damping float64) float64 {
+	out := 0.0
+	for i := range r.combs {
+		out += r.combs[i].process(in, feedback, damping)
+	}
+
+	for i := range r.allpasses {
+		out = r.allpasses[i].process(out)
+	}
+
+	return out
+}
+
+// Reverb is a Freeverb style algorithmic reverb: each channel goes through
+// eight parallel lowpass feedback combs followed by four allpasses.
+type Reverb struct {
+	description audiograph.ComponentDescription
+
+	samplingFrequency uint32
+	predelay          *delayLine
+	predelayTap       delayTap
+	left, right       reverbChannel
+}
+
+func NewReverb() *Reverb {
+	return &Reverb{
+		description: audiograph.ComponentDescription{
+			Inputs: []audiograph.ComponentInput{
+				{
+					Name:        "left",
+					Description: "left channel",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "right",
+					Description: "right channel",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "room_size",
+					Description: "length of the tail. from 0 to 1",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 0.5,
+					},
+				},
+				{
+					Name:        "damping",
+					Description: "how fast the high frequencies fade compared to the low ones. from 0 to 1",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 0.5,
+					},
+				},
+				{
+					Name:        "width",
+					Description: "stereo width of the reverberated signal. from 0 (mono) to 1",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 1.0,
+					},
+				},
+				{
+					Name:        "predelay",
+					Description: "delay before the reverberation starts, in seconds. from 0 to 0.5",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "mix",
+					Description: "balance between the dry (0) and the reverberated (1) signals",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 0.3,
+					},
+				},
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "left",
+					Description: "left channel",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "right",
+					Description: "right channel",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *Reverb) GetDescription() *audiograph.ComponentDescription {
+	return &r.description
+}
+
+// Prepare allocates the predelay line and the filters, which are tuned for
+// the sampling frequency of the graph.
+func (r *Reverb) Prepare(samplingFrequency uint32) {
+	if samplingFrequency == r.samplingFrequency {
+		return
+	}
+
+	r.samplingFrequency = samplingFrequency
+	r.predelay = newDelayLine(int(math.Ceil(reverbMaxPredelay * float64(samplingFrequency))))
+	r.left = newReverbChannel(samplingFrequency, 0)
+	r.right = newReverbChannel(samplingFrequency, reverbStereoSpread)
+}
+
+func (r *Reverb) Execute(ctx audiograph.ExecutionContext) error {
+	left := r.description.Inputs[0].Value.Float
+	right := r.description.Inputs[1].Value.Float
+	roomSize := clamp(r.description.Inputs[2].Value.Float, 0, 1)
+	damping := clamp(r.description.Inputs[3].Value.Float, 0, 1)
+	width := clamp(r.description.Inputs[4].Value.Float, 0, 1)
+	predelay := clamp(r.description.Inputs[5].Value.Float, 0, reverbMaxPredelay)
+	mix := clamp(r.description.Inputs[6].Value.Float, 0, 1)
+
+	// Only happens when the component is used outside of a graph, which
+	// otherwise calls Prepare beforehand
+	r.Prepare(ctx.SamplingFrequency)
+
+	// Both channels feed the same mono reverberation, the width coming from the
+	// different tunings of the two channels
+	in := (left + right) * 0.015
+
+	delayed := r.predelayTap.read(r.predelay, predelay*float64(ctx.SamplingFrequency))
+	r.predelay.write(in)
+	if predelay > 0 {
+		in = delayed
+	}
+
+	feedback := roomSize*0.28 + 0.7
+	damping *= 0.4
+
+	wetLeft := r.left.process(in, feedback, damping)
+	wetRight := r.right.process(in, feedback, damping)
+
+	// Freeverb scales its wet signal by 3
+	wet1 := mix * 3 * (width/2 + 0.5)
+	wet2 := mix * 3 * (1 - width) / 2
+
+	r.description.Outputs[0].Value.Float = left*(1-mix) + wetLeft*wet1 + wetRight*wet2
+	r.description.Outputs[1].Value.Float = right*(1-mix) + wetRight*wet1 + wetLeft*wet2
+
+	return nil
+}
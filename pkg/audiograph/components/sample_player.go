@@ -0,0 +1,200 @@
+package components
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+	"github.com/sywesk/audiomix/pkg/wav"
+)
+
+type SamplePlayer struct {
+	description audiograph.ComponentDescription
+
+	// audio is the file as decoded, channels holds its first two channels
+	// resampled to channelsRate.
+	audio        *wav.Audio
+	channels     [][]float64
+	channelsRate uint32
+
+	position    float64
+	playing     bool
+	lastTrigger bool
+}
+
+func NewSamplePlayer() *SamplePlayer {
+	return &SamplePlayer{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				{
+					Name:        "path",
+					Description: "path of the WAV file to play, PCM or float",
+					Value: audiograph.Value{
+						Type: audiograph.StringValueType,
+					},
+				},
+			},
+			Inputs: []audiograph.ComponentInput{
+				{
+					Name:        "trigger",
+					Description: "restarts the playback from the beginning when going from false to true",
+					Value: audiograph.Value{
+						Type: audiograph.BoolValueType,
+					},
+				},
+				{
+					Name:        "loop",
+					Description: "when true, the playback restarts from the beginning once the end is reached",
+					Value: audiograph.Value{
+						Type: audiograph.BoolValueType,
+					},
+				},
+				{
+					Name:        "speed",
+					Description: "playback speed. 1 is the original speed, negative values play backwards",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 1.0,
+					},
+				},
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "left",
+					Description: "left channel of the file, or its only channel for mono files",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "right",
+					Description: "right channel of the file, or its only channel for mono files",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *SamplePlayer) GetDescription() *audiograph.ComponentDescription {
+	return &s.description
+}
+
+func (s *SamplePlayer) OnParameterChange(name string) error {
+	if name != "path" {
+		return nil
+	}
+
+	audio, err := wav.ReadFile(s.description.Parameters[0].Value.String)
+	if err != nil {
+		return fmt.Errorf("failed to load sample: %w", err)
+	}
+
+	s.audio = audio
+	s.channels = nil
+	s.channelsRate = 0
+	s.position = 0
+	s.playing = true
+
+	return nil
+}
+
+// Prepare resamples the file to the sampling frequency of the graph, which is
+// too slow to be done while playing.
+func (s *SamplePlayer) Prepare(samplingFrequency uint32) {
+	if s.audio == nil || s.audio.Frames() == 0 || s.channelsRate == samplingFrequency {
+		return
+	}
+
+	s.resample(samplingFrequency)
+}
+
+func (s *SamplePlayer) Execute(ctx audiograph.ExecutionContext) error {
+	trigger := s.description.Inputs[0].Value.Bool
+	loop := s.description.Inputs[1].Value.Bool
+	speed := s.description.Inputs[2].Value.Float
+
+	s.description.Outputs[0].Value.Float = 0
+	s.description.Outputs[1].Value.Float = 0
+
+	if s.audio == nil || s.audio.Frames() == 0 {
+		return nil
+	}
+
+	// Only happens when the component is used outside of a graph, which
+	// otherwise calls Prepare beforehand
+	if s.channelsRate != ctx.SamplingFrequency {
+		s.resample(ctx.SamplingFrequency)
+	}
+
+	frames := float64(len(s.channels[0]))
+
+	if trigger && !s.lastTrigger {
+		s.playing = true
+		s.position = 0
+		if speed < 0 {
+			s.position = frames - 1
+		}
+	}
+	s.lastTrigger = trigger
+
+	if !s.playing {
+		return nil
+	}
+
+	s.description.Outputs[0].Value.Float = s.read(s.channels[0])
+	s.description.Outputs[1].Value.Float = s.read(s.channels[len(s.channels)-1])
+
+	s.position += speed
+
+	if s.position >= frames || s.position < 0 {
+		if loop {
+			s.position = math.Mod(s.position, frames)
+			if s.position < 0 {
+				s.position += frames
+			}
+		} else {
+			s.playing = false
+		}
+	}
+
+	return nil
+}
+
+func (s *SamplePlayer) resample(samplingFrequency uint32) {
+	channelCount := len(s.audio.Channels)
+	if channelCount > 2 {
+		channelCount = 2
+	}
+
+	s.channels = make([][]float64, channelCount)
+	for i := range s.channels {
+		s.channels[i] = resample(s.audio.Channels[i], s.audio.SampleRate, samplingFrequency)
+	}
+
+	// Keep the playback at the same point of the file
+	if s.channelsRate != 0 {
+		s.position *= float64(samplingFrequency) / float64(s.channelsRate)
+	}
+	s.channelsRate = samplingFrequency
+}
+
+// read returns the sample at the current position, linearly interpolating
+// between the two closest frames.
+func (s *SamplePlayer) read(samples []float64) float64 {
+	index := int(s.position)
+	fraction := s.position - float64(index)
+
+	if index >= len(samples) {
+		return samples[len(samples)-1]
+	}
+
+	next := index + 1
+	if next >= len(samples) {
+		next = 0
+	}
+
+	return samples[index]*(1-fraction) + samples[next]*fraction
+}
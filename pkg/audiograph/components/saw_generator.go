@@ -0,0 +1,48 @@
+package components
+
+import (
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// SawGenerator generates a rising sawtooth, anti-aliased with PolyBLEP.
+type SawGenerator struct {
+	description audiograph.ComponentDescription
+	oscillator  oscillator
+}
+
+func NewSawGenerator() *SawGenerator {
+	return &SawGenerator{
+		description: audiograph.ComponentDescription{
+			Inputs: oscillatorInputs("sawtooth"),
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "saw",
+					Description: "sawtooth curve",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *SawGenerator) GetDescription() *audiograph.ComponentDescription {
+	return &s.description
+}
+
+func (s *SawGenerator) Execute(ctx audiograph.ExecutionContext) error {
+	freq := s.description.Inputs[0].Value.Float
+	gain := s.description.Inputs[1].Value.Float
+	offset := s.description.Inputs[2].Value.Float
+	sync := s.description.Inputs[3].Value.Float
+
+	phase, dt := s.oscillator.advance(freq, sync, ctx.SamplingFrequency)
+
+	value := 2*phase - 1
+	value -= polyBLEP(phase, dt)
+
+	s.description.Outputs[0].Value.Float = value*gain + offset
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package components
+
+import (
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// Scale linearly maps its input from one range to another, which turns for
+// instance a -1 to 1 LFO into a 200 to 2000 Hz cutoff. Values outside of the
+// input range are extrapolated, a Clamp can follow when they must not be.
+type Scale struct {
+	description audiograph.ComponentDescription
+}
+
+func NewScale() *Scale {
+	return &Scale{
+		description: audiograph.ComponentDescription{
+			Inputs: []audiograph.ComponentInput{
+				floatInput("in", "signal to map", 0),
+				floatInput("in_min", "input value mapped to out_min", -1),
+				floatInput("in_max", "input value mapped to out_max", 1),
+				floatInput("out_min", "output value for in_min", 0),
+				floatInput("out_max", "output value for in_max", 1),
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "out",
+					Description: "mapped signal",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *Scale) GetDescription() *audiograph.ComponentDescription {
+	return &s.description
+}
+
+func (s *Scale) Execute(ctx audiograph.ExecutionContext) error {
+	in := s.description.Inputs[0].Value.Float
+	inMin := s.description.Inputs[1].Value.Float
+	inMax := s.description.Inputs[2].Value.Float
+	outMin := s.description.Inputs[3].Value.Float
+	outMax := s.description.Inputs[4].Value.Float
+
+	// An empty input range maps everything to out_min
+	position := 0.0
+	if inMax != inMin {
+		position = (in - inMin) / (inMax - inMin)
+	}
+
+	s.description.Outputs[0].Value.Float = outMin + position*(outMax-outMin)
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package components
+
+import (
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// SquareGenerator generates a square wave, anti-aliased with PolyBLEP.
+type SquareGenerator struct {
+	description audiograph.ComponentDescription
+	oscillator  oscillator
+}
+
+func NewSquareGenerator() *SquareGenerator {
+	return &SquareGenerator{
+		description: audiograph.ComponentDescription{
+			Inputs: oscillatorInputs("square"),
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "square",
+					Description: "square curve",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *SquareGenerator) GetDescription() *audiograph.ComponentDescription {
+	return &s.description
+}
+
+func (s *SquareGenerator) Execute(ctx audiograph.ExecutionContext) error {
+	freq := s.description.Inputs[0].Value.Float
+	gain := s.description.Inputs[1].Value.Float
+	offset := s.description.Inputs[2].Value.Float
+	sync := s.description.Inputs[3].Value.Float
+
+	phase, dt := s.oscillator.advance(freq, sync, ctx.SamplingFrequency)
+
+	s.description.Outputs[0].Value.Float = pulse(phase, dt, 0.5)*gain + offset
+
+	return nil
+}
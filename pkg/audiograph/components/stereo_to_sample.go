@@ -0,0 +1,50 @@
+package components
+
+import "github.com/sywesk/audiomix/pkg/audiograph"
+
+type StereoToSample struct {
+	description audiograph.ComponentDescription
+}
+
+func NewStereoToSample() *StereoToSample {
+	return &StereoToSample{
+		description: audiograph.ComponentDescription{
+			Inputs: []audiograph.ComponentInput{
+				{
+					Name:        "left",
+					Description: "float to convert into the left channel of the audio signal",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "right",
+					Description: "float to convert into the right channel of the audio signal",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "sample",
+					Description: "converted audio",
+					Value: audiograph.Value{
+						Type: audiograph.SampleValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *StereoToSample) GetDescription() *audiograph.ComponentDescription {
+	return &s.description
+}
+
+func (s *StereoToSample) Execute(ctx audiograph.ExecutionContext) error {
+	s.description.Outputs[0].Value.Sample.Left = audiograph.SampleType(s.description.Inputs[0].Value.Float)
+	s.description.Outputs[0].Value.Sample.Right = audiograph.SampleType(s.description.Inputs[1].Value.Float)
+
+	return nil
+}
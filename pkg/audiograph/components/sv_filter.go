@@ -0,0 +1,107 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// SVFilter is a zero-delay feedback state variable filter, as described by
+// Andrew Simper. Its trapezoidal integrators keep it stable when the cutoff is
+// modulated at audio rate.
+type SVFilter struct {
+	description audiograph.ComponentDescription
+
+	cutoff            float64
+	samplingFrequency uint32
+	g                 float64
+
+	ic1eq, ic2eq float64
+}
+
+func NewSVFilter() *SVFilter {
+	return &SVFilter{
+		description: audiograph.ComponentDescription{
+			Inputs: []audiograph.ComponentInput{
+				{
+					Name:        "in",
+					Description: "signal to filter",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "cutoff",
+					Description: "cutoff frequency, in Hz",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: 1000.0,
+					},
+				},
+				{
+					Name:        "q",
+					Description: "quality factor. higher values give a resonant peak. min 0.01",
+					Value: audiograph.Value{
+						Type:  audiograph.FloatValueType,
+						Float: math.Sqrt2 / 2,
+					},
+				},
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "lp",
+					Description: "lowpass output",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "bp",
+					Description: "bandpass output",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "hp",
+					Description: "highpass output",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *SVFilter) GetDescription() *audiograph.ComponentDescription {
+	return &s.description
+}
+
+func (s *SVFilter) Execute(ctx audiograph.ExecutionContext) error {
+	in := s.description.Inputs[0].Value.Float
+	cutoff := s.description.Inputs[1].Value.Float
+	k := 1 / math.Max(s.description.Inputs[2].Value.Float, 0.01)
+
+	if cutoff != s.cutoff || ctx.SamplingFrequency != s.samplingFrequency {
+		s.cutoff, s.samplingFrequency = cutoff, ctx.SamplingFrequency
+		s.g = prewarp(cutoff, ctx.SamplingFrequency)
+	}
+
+	a1 := 1 / (1 + s.g*(s.g+k))
+	a2 := s.g * a1
+	a3 := s.g * a2
+
+	v3 := in - s.ic2eq
+	v1 := a1*s.ic1eq + a2*v3
+	v2 := s.ic2eq + a2*s.ic1eq + a3*v3
+
+	s.ic1eq = 2*v1 - s.ic1eq
+	s.ic2eq = 2*v2 - s.ic2eq
+
+	s.description.Outputs[0].Value.Float = v2
+	s.description.Outputs[1].Value.Float = v1
+	s.description.Outputs[2].Value.Float = in - k*v1 - v2
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package components
+
+import "github.com/sywesk/audiomix/pkg/audiograph"
+
+// Threshold turns a float signal into a bool one, typically to drive the gate
+// of an ADSR from an oscillator.
+type Threshold struct {
+	description audiograph.ComponentDescription
+}
+
+func NewThreshold() *Threshold {
+	return &Threshold{
+		description: audiograph.ComponentDescription{
+			Inputs: []audiograph.ComponentInput{
+				{
+					Name:        "in",
+					Description: "signal to compare to the threshold",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+				{
+					Name:        "threshold",
+					Description: "value above which the output is true",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "gate",
+					Description: "true while the input is strictly above the threshold",
+					Value: audiograph.Value{
+						Type: audiograph.BoolValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (t *Threshold) GetDescription() *audiograph.ComponentDescription {
+	return &t.description
+}
+
+func (t *Threshold) Execute(ctx audiograph.ExecutionContext) error {
+	t.description.Outputs[0].Value.Bool = t.description.Inputs[0].Value.Float > t.description.Inputs[1].Value.Float
+	return nil
+}
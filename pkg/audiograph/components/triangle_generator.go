@@ -0,0 +1,55 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// TriangleGenerator generates a triangle wave, its corners being anti-aliased
+// with PolyBLAMP.
+type TriangleGenerator struct {
+	description audiograph.ComponentDescription
+	oscillator  oscillator
+}
+
+func NewTriangleGenerator() *TriangleGenerator {
+	return &TriangleGenerator{
+		description: audiograph.ComponentDescription{
+			Inputs: oscillatorInputs("triangle"),
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "triangle",
+					Description: "triangle curve",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (t *TriangleGenerator) GetDescription() *audiograph.ComponentDescription {
+	return &t.description
+}
+
+func (t *TriangleGenerator) Execute(ctx audiograph.ExecutionContext) error {
+	freq := t.description.Inputs[0].Value.Float
+	gain := t.description.Inputs[1].Value.Float
+	offset := t.description.Inputs[2].Value.Float
+	sync := t.description.Inputs[3].Value.Float
+
+	phase, dt := t.oscillator.advance(freq, sync, ctx.SamplingFrequency)
+
+	// Lowest at phase 0, highest at phase 0.5
+	value := 1 - 4*math.Abs(phase-0.5)
+
+	// The slope goes from -4 to 4 per period at phase 0, and back at phase 0.5
+	value += 4 * dt * polyBLAMP(phase, dt)
+	value -= 4 * dt * polyBLAMP(wrapPhase(phase+0.5), dt)
+
+	t.description.Outputs[0].Value.Float = value*gain + offset
+
+	return nil
+}
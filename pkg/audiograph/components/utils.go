@@ -0,0 +1,47 @@
+package components
+
+import (
+	"math"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+func clamp(value float64, min float64, max float64) float64 {
+	if value > max {
+		return max
+	} else if value < min {
+		return min
+	}
+
+	return value
+}
+
+// prewarp returns the gain of a trapezoidal integrator having the given cutoff,
+// as used by zero-delay feedback filters. The cutoff is kept strictly below the
+// Nyquist frequency, where the gain would be infinite.
+func prewarp(cutoff float64, samplingFrequency uint32) float64 {
+	sr := float64(samplingFrequency)
+	return math.Tan(math.Pi * clamp(cutoff, 1, sr*0.49) / sr)
+}
+
+// hermite interpolates between x0 and x1 with a 4-point, 3rd-order Hermite
+// polynomial, frac going from 0 (x0) to 1 (x1).
+func hermite(xm1, x0, x1, x2, frac float64) float64 {
+	c1 := 0.5 * (x1 - xm1)
+	c2 := xm1 - 2.5*x0 + 2*x1 - 0.5*x2
+	c3 := 0.5*(x2-xm1) + 1.5*(x0-x1)
+
+	return ((c3*frac+c2)*frac+c1)*frac + x0
+}
+
+// floatInput returns a float input having the given default value.
+func floatInput(name, description string, value float64) audiograph.ComponentInput {
+	return audiograph.ComponentInput{
+		Name:        name,
+		Description: description,
+		Value: audiograph.Value{
+			Type:  audiograph.FloatValueType,
+			Float: value,
+		},
+	}
+}
@@ -0,0 +1,189 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+var (
+	ErrUnknownCurve        = fmt.Errorf("unknown curve")
+	ErrInvalidCurveTable   = fmt.Errorf("invalid curve table")
+	ErrInvalidOversampling = fmt.Errorf("invalid oversampling")
+)
+
+// A waveshaperCurve maps the driven input to the output.
+type waveshaperCurve func(x float64) float64
+
+var (
+	waveshaperCurves = map[string]waveshaperCurve{
+		"tanh": math.Tanh,
+		// Cubic soft clipper, reaching 1 for an input of 1 with a null slope
+		"softclip": func(x float64) float64 {
+			if x >= 1 {
+				return 1
+			} else if x <= -1 {
+				return -1
+			}
+
+			return 1.5*x - 0.5*x*x*x
+		},
+		// Reflects the signal back every time it crosses ±1
+		"foldback": func(x float64) float64 {
+			folded := math.Mod(x-1, 4)
+			if folded < 0 {
+				folded += 4
+			}
+
+			return math.Abs(folded-2) - 1
+		},
+		// The negative half saturates sooner and lower, which adds even
+		// harmonics like a triode does
+		"tube": func(x float64) float64 {
+			if x >= 0 {
+				return 1 - math.Exp(-x)
+			}
+
+			return (math.Exp(1.5*x) - 1) / 1.5
+		},
+	}
+)
+
+// Waveshaper distorts its input through a transfer curve, optionally running
+// at a multiple of the sampling rate to limit aliasing.
+type Waveshaper struct {
+	description audiograph.ComponentDescription
+	curve       waveshaperCurve
+	oversampler *oversampler
+}
+
+func NewWaveshaper() *Waveshaper {
+	return &Waveshaper{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				{
+					Name:        "curve",
+					Description: "transfer curve: tanh (default), softclip, foldback, tube or table",
+					Value: audiograph.Value{
+						Type:   audiograph.StringValueType,
+						String: "tanh",
+					},
+				},
+				{
+					Name:        "table",
+					Description: "transfer curve of the table mode, as a list of outputs evenly spread on inputs from -1 to 1",
+					Value: audiograph.Value{
+						Type: audiograph.FloatListValueType,
+					},
+				},
+				{
+					Name:        "oversampling",
+					Description: "factor by which the sampling rate is raised while shaping: 1 (default), 2, 4 or 8",
+					Value: audiograph.Value{
+						Type:    audiograph.IntegerValueType,
+						Integer: 1,
+					},
+				},
+			},
+			Inputs: []audiograph.ComponentInput{
+				floatInput("in", "signal to distort", 0),
+				floatInput("drive", "gain applied before the curve. min 0", 1),
+				floatInput("mix", "balance between the dry (0) and the distorted (1) signals", 1),
+			},
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "out",
+					Description: "distorted signal",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+		curve:       waveshaperCurves["tanh"],
+		oversampler: newOversampler(1),
+	}
+}
+
+func (w *Waveshaper) GetDescription() *audiograph.ComponentDescription {
+	return &w.description
+}
+
+func (w *Waveshaper) OnParameterChange(name string) error {
+	switch name {
+	case "curve":
+		curve := w.description.Parameters[0].Value.String
+		if strings.EqualFold(curve, "table") {
+			// The table may be given after the curve, the signal goes through
+			// untouched until then
+			w.curve = nil
+			if table := w.description.Parameters[1].Value.Floats; len(table) >= 2 {
+				w.curve = tableCurve(table)
+			}
+
+			return nil
+		}
+
+		shape, ok := waveshaperCurves[strings.ToLower(curve)]
+		if !ok {
+			return fmt.Errorf("curve '%s': %w", curve, ErrUnknownCurve)
+		}
+
+		w.curve = shape
+	case "table":
+		table := w.description.Parameters[1].Value.Floats
+		if len(table) < 2 {
+			return fmt.Errorf("%d points, at least 2 are needed: %w", len(table), ErrInvalidCurveTable)
+		}
+
+		if strings.EqualFold(w.description.Parameters[0].Value.String, "table") {
+			w.curve = tableCurve(table)
+		}
+	case "oversampling":
+		factor := w.description.Parameters[2].Value.Integer
+		if factor != 1 && factor != 2 && factor != 4 && factor != 8 {
+			return fmt.Errorf("factor %d: %w", factor, ErrInvalidOversampling)
+		}
+
+		w.oversampler = newOversampler(int(factor))
+	}
+
+	return nil
+}
+
+func (w *Waveshaper) Execute(ctx audiograph.ExecutionContext) error {
+	in := w.description.Inputs[0].Value.Float
+	drive := math.Max(w.description.Inputs[1].Value.Float, 0)
+	mix := clamp(w.description.Inputs[2].Value.Float, 0, 1)
+
+	if w.curve == nil {
+		w.description.Outputs[0].Value.Float = in
+		return nil
+	}
+
+	wet := w.oversampler.process(in*drive, w.curve)
+
+	w.description.Outputs[0].Value.Float = in*(1-mix) + wet*mix
+
+	return nil
+}
+
+// tableCurve returns a curve linearly interpolating the given outputs, spread
+// on inputs from -1 to 1. Inputs outside of this range are clamped.
+func tableCurve(table []float64) waveshaperCurve {
+	points := append([]float64(nil), table...)
+
+	return func(x float64) float64 {
+		position := (clamp(x, -1, 1) + 1) / 2 * float64(len(points)-1)
+		n := int(position)
+		if n >= len(points)-1 {
+			return points[len(points)-1]
+		}
+
+		frac := position - float64(n)
+
+		return points[n]*(1-frac) + points[n+1]*frac
+	}
+}
@@ -0,0 +1,293 @@
+package components
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+	"github.com/sywesk/audiomix/pkg/wav"
+)
+
+var (
+	ErrInvalidWavetable = fmt.Errorf("invalid wavetable")
+)
+
+const (
+	// wavetableSize is the number of samples of every band-limited table.
+	wavetableSize = 2048
+	// wavetableLevels is the number of mip-map levels, each one holding half
+	// the harmonics of the previous one, down to the fundamental alone.
+	wavetableLevels = 11
+	// defaultWavetableFrameSize is the size of the frames of WAV wavetables,
+	// when not given and when it divides the file.
+	defaultWavetableFrameSize = 2048
+)
+
+// WavetableOscillator plays single-cycle waveforms, called frames, loaded from
+// a WAV file or given inline as a list. Each frame is turned into a set of
+// band-limited tables, one per octave, and the position input morphs between
+// consecutive frames.
+type WavetableOscillator struct {
+	description audiograph.ComponentDescription
+	oscillator  oscillator
+
+	// samples holds the waveforms as loaded, before being split into frames
+	samples []float64
+	// tables is indexed by frame, then by mip-map level
+	tables [][][]float64
+}
+
+func NewWavetableOscillator() *WavetableOscillator {
+	inputs := append(oscillatorInputs("waveform"), audiograph.ComponentInput{
+		Name:        "position",
+		Description: "position in the wavetable, from 0 (first frame) to 1 (last frame). frames in between are crossfaded",
+		Value: audiograph.Value{
+			Type: audiograph.FloatValueType,
+		},
+	})
+
+	return &WavetableOscillator{
+		description: audiograph.ComponentDescription{
+			Parameters: []audiograph.ComponentParameter{
+				{
+					Name:        "path",
+					Description: "WAV file holding the frames one after the other. only its first channel is used",
+					Value: audiograph.Value{
+						Type: audiograph.StringValueType,
+					},
+				},
+				{
+					Name:        "table",
+					Description: "inline frames, as a list of samples. exclusive with path",
+					Value: audiograph.Value{
+						Type: audiograph.FloatListValueType,
+					},
+				},
+				{
+					Name:        "frame_size",
+					Description: "number of samples of each frame. defaults to 2048 for files holding a multiple of it, and otherwise to the whole file or list",
+					Value: audiograph.Value{
+						Type: audiograph.IntegerValueType,
+					},
+				},
+			},
+			Inputs: inputs,
+			Outputs: []audiograph.ComponentOutput{
+				{
+					Name:        "waveform",
+					Description: "wavetable curve",
+					Value: audiograph.Value{
+						Type: audiograph.FloatValueType,
+					},
+				},
+			},
+		},
+	}
+}
+
+func (w *WavetableOscillator) GetDescription() *audiograph.ComponentDescription {
+	return &w.description
+}
+
+func (w *WavetableOscillator) OnParameterChange(name string) error {
+	path := w.description.Parameters[0].Value.String
+	table := w.description.Parameters[1].Value.Floats
+	frameSize := int(w.description.Parameters[2].Value.Integer)
+
+	if path != "" && len(table) > 0 {
+		return fmt.Errorf("path and table cannot be both set: %w", ErrInvalidWavetable)
+	}
+
+	// Nothing changes until the new frames are built, the previous ones are
+	// kept on error
+	samples := w.samples
+
+	switch name {
+	case "path":
+		audio, err := wav.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to load wavetable: %w", err)
+		}
+
+		if audio.Frames() == 0 {
+			return fmt.Errorf("empty file %s: %w", path, ErrInvalidWavetable)
+		}
+
+		samples = audio.Channels[0]
+	case "table":
+		samples = table
+	case "frame_size":
+		if frameSize < 0 {
+			return fmt.Errorf("negative frame size: %w", ErrInvalidWavetable)
+		}
+	default:
+		return nil
+	}
+
+	// The frame size may be given before or after the frames: they are split
+	// again each time either changes
+	tables, err := splitWavetable(samples, frameSize, path != "")
+	if err != nil {
+		return err
+	}
+
+	w.samples = samples
+	w.tables = tables
+
+	return nil
+}
+
+// splitWavetable cuts samples into frames and builds their tables. Without a
+// frame size, files are cut in frames of the default size when it divides
+// them, and are otherwise a single frame, like inline tables.
+func splitWavetable(samples []float64, frameSize int, file bool) ([][][]float64, error) {
+	if len(samples) == 0 {
+		return nil, nil
+	}
+
+	if frameSize == 0 {
+		frameSize = len(samples)
+		if file && len(samples)%defaultWavetableFrameSize == 0 {
+			frameSize = defaultWavetableFrameSize
+		}
+	}
+
+	if len(samples)%frameSize != 0 || frameSize < 2 {
+		return nil, fmt.Errorf("%d samples cannot be split in frames of %d: %w", len(samples), frameSize, ErrInvalidWavetable)
+	}
+
+	var tables [][][]float64
+	for offset := 0; offset < len(samples); offset += frameSize {
+		tables = append(tables, buildMipMaps(samples[offset:offset+frameSize]))
+	}
+
+	return tables, nil
+}
+
+// buildMipMaps computes the spectrum of a frame, then renders one table per
+// level keeping only the harmonics allowed at that level.
+func buildMipMaps(frame []float64) [][]float64 {
+	spectrum := frameSpectrum(frame)
+
+	levels := make([][]float64, wavetableLevels)
+	for level := range levels {
+		maxHarmonic := (wavetableSize / 2) >> level
+
+		// DC is dropped, oscillators are centered on their offset input
+		bins := make([]complex128, wavetableSize)
+		for harmonic := 1; harmonic < len(spectrum) && harmonic <= maxHarmonic && harmonic < wavetableSize/2; harmonic++ {
+			bins[harmonic] = spectrum[harmonic]
+			bins[wavetableSize-harmonic] = cmplx.Conj(spectrum[harmonic])
+		}
+
+		fft(bins, true)
+
+		levels[level] = make([]float64, wavetableSize)
+		for i := range bins {
+			levels[level][i] = real(bins[i])
+		}
+	}
+
+	return levels
+}
+
+// frameSpectrum returns the harmonics of a single-cycle frame, scaled as if the
+// frame held wavetableSize samples.
+func frameSpectrum(frame []float64) []complex128 {
+	scale := complex(float64(wavetableSize)/float64(len(frame)), 0)
+	harmonics := len(frame) / 2
+	if harmonics > wavetableSize/2 {
+		harmonics = wavetableSize / 2
+	}
+
+	spectrum := make([]complex128, harmonics+1)
+
+	if isPowerOfTwo(len(frame)) {
+		bins := make([]complex128, len(frame))
+		for i, sample := range frame {
+			bins[i] = complex(sample, 0)
+		}
+
+		fft(bins, false)
+		copy(spectrum, bins)
+	} else {
+		// Plain DFT, inline frames are usually small
+		for harmonic := range spectrum {
+			for i, sample := range frame {
+				angle := -2 * math.Pi * float64(harmonic*i) / float64(len(frame))
+				spectrum[harmonic] += complex(sample*math.Cos(angle), sample*math.Sin(angle))
+			}
+		}
+	}
+
+	for harmonic := range spectrum {
+		spectrum[harmonic] *= scale
+	}
+
+	// The Nyquist bin of even frames holds both the positive and negative
+	// frequency, it only counts once
+	if len(frame)%2 == 0 && harmonics == len(frame)/2 {
+		spectrum[harmonics] /= 2
+	}
+
+	return spectrum
+}
+
+func (w *WavetableOscillator) Execute(ctx audiograph.ExecutionContext) error {
+	freq := w.description.Inputs[0].Value.Float
+	gain := w.description.Inputs[1].Value.Float
+	offset := w.description.Inputs[2].Value.Float
+	sync := w.description.Inputs[3].Value.Float
+	position := clamp(w.description.Inputs[4].Value.Float, 0, 1)
+
+	phase, _ := w.oscillator.advance(freq, sync, ctx.SamplingFrequency)
+
+	if len(w.tables) == 0 {
+		w.description.Outputs[0].Value.Float = offset
+		return nil
+	}
+
+	level := wavetableLevel(freq, ctx.SamplingFrequency)
+
+	// Crossfade between the two frames surrounding the position
+	framePosition := position * float64(len(w.tables)-1)
+	frame := int(framePosition)
+	fraction := framePosition - float64(frame)
+
+	value := readTable(w.tables[frame][level], phase)
+	if fraction > 0 {
+		value = value*(1-fraction) + readTable(w.tables[frame+1][level], phase)*fraction
+	}
+
+	w.description.Outputs[0].Value.Float = value*gain + offset
+
+	return nil
+}
+
+// wavetableLevel returns the first mip-map level whose harmonics all stay below
+// the Nyquist frequency at the given frequency.
+func wavetableLevel(freq float64, samplingFrequency uint32) int {
+	freq = math.Abs(freq)
+	if freq == 0 {
+		return 0
+	}
+
+	allowedHarmonics := float64(samplingFrequency) / 2 / freq
+	level := int(math.Ceil(math.Log2(float64(wavetableSize/2) / allowedHarmonics)))
+
+	return int(clamp(float64(level), 0, wavetableLevels-1))
+}
+
+// readTable reads a table at a phase between 0 and 1, linearly interpolating
+// between samples.
+func readTable(table []float64, phase float64) float64 {
+	position := phase * float64(len(table))
+	index := int(position)
+	fraction := position - float64(index)
+
+	index %= len(table)
+	next := (index + 1) % len(table)
+
+	return table[index]*(1-fraction) + table[next]*fraction
+}
@@ -0,0 +1,43 @@
+package components
+
+import (
+	"github.com/sywesk/audiomix/pkg/audiograph"
+)
+
+// WhiteNoise generates noise with the same power at every frequency.
+type WhiteNoise struct {
+	description audiograph.ComponentDescription
+	source      noiseSource
+}
+
+func NewWhiteNoise() *WhiteNoise {
+	return &WhiteNoise{
+		description: noiseDescription("white"),
+		source:      newNoiseSource(),
+	}
+}
+
+func (w *WhiteNoise) GetDescription() *audiograph.ComponentDescription {
+	return &w.description
+}
+
+func (w *WhiteNoise) OnParameterChange(name string) error {
+	if name == "seed" {
+		w.source.seedFromParameter(w.description.Parameters[0].Value.Integer)
+	}
+
+	return nil
+}
+
+func (w *WhiteNoise) Seed(seed int64) {
+	w.source.seedFromGraph(seed)
+}
+
+func (w *WhiteNoise) Execute(ctx audiograph.ExecutionContext) error {
+	gain := w.description.Inputs[0].Value.Float
+	offset := w.description.Inputs[1].Value.Float
+
+	w.description.Outputs[0].Value.Float = w.source.next()*gain + offset
+
+	return nil
+}
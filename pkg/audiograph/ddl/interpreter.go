@@ -6,6 +6,8 @@ import (
 	"github.com/sywesk/audiomix/pkg/audiograph"
 	"github.com/sywesk/audiomix/pkg/audiograph/components"
 	"io"
+	"strconv"
+	"strings"
 )
 
 type IParser interface {
@@ -68,6 +70,10 @@ func (i *interpreter) BuildGraph() error {
 }
 
 func (i *interpreter) handleParameterStatement(stmt *ParameterStatement) error {
+	if stmt.Target != nil && stmt.Name != "OUTPUT" {
+		return fmt.Errorf("line %d: %s does not take a connector: %w", stmt.Line, stmt.Name, ErrSyntaxError)
+	}
+
 	switch stmt.Name {
 	case "SAMPLING_FREQ":
 		if stmt.Value.Type != audiograph.IntegerValueType {
@@ -75,6 +81,57 @@ func (i *interpreter) handleParameterStatement(stmt *ParameterStatement) error {
 		}
 		i.graph.SetSamplingFrequency(uint32(stmt.Value.Integer))
 
+	case "SEED":
+		if stmt.Value.Type != audiograph.IntegerValueType {
+			return fmt.Errorf("line %d: SEED expects an integer", stmt.Line)
+		}
+		i.graph.SetSeed(stmt.Value.Integer)
+
+	case "OUTPUT_FORMAT":
+		if stmt.Value.Type != audiograph.StringValueType {
+			return fmt.Errorf("line %d: OUTPUT_FORMAT expects a string", stmt.Line)
+		}
+
+		format, err := audiograph.ParseOutputFormat(stmt.Value.String)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", stmt.Line, err)
+		}
+
+		err = i.graph.SetOutputFormat(format)
+		if err != nil {
+			return fmt.Errorf("line %d: failed to set output format: %w", stmt.Line, err)
+		}
+
+	case "CHANNELS":
+		if stmt.Value.Type != audiograph.IntegerValueType {
+			return fmt.Errorf("line %d: CHANNELS expects an integer", stmt.Line)
+		}
+
+		err := i.graph.SetChannels(int(stmt.Value.Integer))
+		if err != nil {
+			return fmt.Errorf("line %d: failed to set channels: %w", stmt.Line, err)
+		}
+
+	case "OUTPUT":
+		if stmt.Value.Type != audiograph.StringValueType || stmt.Target == nil {
+			return fmt.Errorf("line %d: OUTPUT expects a channel and a connector, as in 'OUTPUT ch1 mixer:left'", stmt.Line)
+		}
+
+		channel, err := parseChannel(stmt.Value.String)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", stmt.Line, err)
+		}
+
+		compID, ok := i.vars[stmt.Target.VariableName]
+		if !ok {
+			return fmt.Errorf("line %d: unknown component '%s'", stmt.Line, stmt.Target.VariableName)
+		}
+
+		err = i.graph.SetChannelOutput(channel, compID, stmt.Target.ConnectorName)
+		if err != nil {
+			return fmt.Errorf("line %d: failed to set output of channel %d: %w", stmt.Line, channel+1, err)
+		}
+
 	case "OUTPUT_COMPONENT":
 		if i.outputComponentIDSet {
 			return fmt.Errorf("line %d: OUTPUT_COMPONENT can be set only once", stmt.Line)
@@ -133,8 +190,10 @@ func (i *interpreter) handleCreateComponent(stmt *CreateComponentStatement) erro
 	compID := i.graph.AddComponent(comp)
 	i.vars[stmt.VariableName] = compID
 
-	for argName, argValue := range stmt.Arguments {
-		err := i.graph.SetParameter(compID, argName, argValue)
+	// Parameters are set in source order, so that components reacting to
+	// several of them behave the same on every load
+	for _, argName := range stmt.ArgumentNames {
+		err := i.graph.SetParameter(compID, argName, stmt.Arguments[argName])
 		if err != nil {
 			return fmt.Errorf("line %d: failed to set parameter '%s': %w", stmt.Line, argName, err)
 		}
@@ -154,10 +213,26 @@ func (i *interpreter) handleConnectStatement(stmt *ConnectStatement) error {
 		return fmt.Errorf("line %d: variable '%s' does not exists: %w", stmt.Line, stmt.To.VariableName, ErrSyntaxError)
 	}
 
-	_, err := i.graph.AddCable(srcID, stmt.From.ConnectorName, dstID, stmt.To.ConnectorName)
+	addCable := i.graph.AddCable
+	if stmt.Feedback {
+		addCable = i.graph.AddFeedbackCable
+	}
+
+	_, err := addCable(srcID, stmt.From.ConnectorName, dstID, stmt.To.ConnectorName)
 	if err != nil {
 		return fmt.Errorf("line %d: failed to add cable: %w", stmt.Line, err)
 	}
 
 	return nil
 }
+
+// parseChannel turns a channel name, from ch1 onwards, into a channel index
+// starting at 0.
+func parseChannel(name string) (int, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(name, "ch"))
+	if err != nil || !strings.HasPrefix(name, "ch") || number < 1 {
+		return 0, fmt.Errorf("invalid channel '%s', channels are named ch1, ch2, ...: %w", name, ErrSyntaxError)
+	}
+
+	return number - 1, nil
+}
@@ -2,6 +2,7 @@ package ddl
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"github.com/sywesk/audiomix/pkg/audiograph"
 	"io"
@@ -21,11 +22,15 @@ const (
 	AtToken                 TokenType = "@"
 	OpeningParenthesisToken TokenType = "("
 	ClosingParenthesisToken TokenType = ")"
+	OpeningBracketToken     TokenType = "["
+	ClosingBracketToken     TokenType = "]"
 	ComaToken               TokenType = ","
 	EqualToken              TokenType = "="
 	ConnectToken            TokenType = "->"
+	FeedbackConnectToken    TokenType = "~>"
 	IdentifierToken         TokenType = "id"
 	NumberToken             TokenType = "n"
+	StringToken             TokenType = "s"
 	ColonToken              TokenType = ":"
 	ReturnToken             TokenType = "r"
 )
@@ -35,8 +40,11 @@ var (
 		"@":  AtToken,
 		"(":  OpeningParenthesisToken,
 		")":  ClosingParenthesisToken,
+		"[":  OpeningBracketToken,
+		"]":  ClosingBracketToken,
 		"=":  EqualToken,
 		"->": ConnectToken,
+		"~>": FeedbackConnectToken,
 		":":  ColonToken,
 		",":  ComaToken,
 		"\n": ReturnToken,
@@ -72,6 +80,9 @@ func (t Token) ToValue() (audiograph.Value, error) {
 			val.Type = audiograph.StringValueType
 			val.String = t.Value
 		}
+	} else if t.Type == StringToken {
+		val.Type = audiograph.StringValueType
+		val.String = t.Value
 	} else if t.Type == NumberToken {
 		if strings.ContainsRune(t.Value, '.') {
 			val.Type = audiograph.FloatValueType
@@ -102,6 +113,8 @@ type lexer struct {
 	readBuffer []byte
 	line       int
 	col        int
+	// prevCol is the column the previous line ended at, to unread its return
+	prevCol int
 }
 
 func newLexer(reader io.Reader) *lexer {
@@ -121,29 +134,68 @@ func (t *lexer) Next() (Token, error) {
 		Col:   t.col,
 	}
 
+	escaped := false
+
 	for {
 		r, _, err := t.reader.ReadRune()
 		if err != nil {
+			if token.Type == StringToken {
+				return token, fmt.Errorf("unterminated string: %s", token.String())
+			}
+
+			// The file may end right after a token: return it, the next call will
+			// report the end of file.
+			if errors.Is(err, io.EOF) && token.Type != UnknownToken {
+				return token, nil
+			}
+
 			return token, err
 		}
 
 		// Update col & line numbers
 		if r == '\n' {
 			t.line++
+			t.prevCol = t.col
 			t.col = 1
 		} else {
 			t.col++
 		}
 
+		// Strings are quoted and may contain anything but line returns. Quotes and
+		// backslashes are escaped with a backslash.
+		if token.Type == StringToken {
+			if r == '\n' {
+				return token, fmt.Errorf("unterminated string: %s", token.String())
+			}
+
+			if !escaped && r == '\\' {
+				escaped = true
+				continue
+			}
+
+			if !escaped && r == '"' {
+				break
+			}
+
+			escaped = false
+			token.Value += string(r)
+			continue
+		}
+
 		// Skip initial spaces
 		if token.Type == UnknownToken && r != '\n' && unicode.IsSpace(r) {
 			continue
 		}
 
+		if token.Type == UnknownToken && r == '"' {
+			token.Type = StringToken
+			continue
+		}
+
 		// If we're in a token, and we reach an end of line, unread the line return
 		// for it to be sent as a token at the next Next() call.
 		if token.Type != UnknownToken && r == '\n' {
-			_ = t.reader.UnreadRune()
+			t.unreadRune(r)
 			break
 		}
 
@@ -159,7 +211,7 @@ func (t *lexer) Next() (Token, error) {
 			}
 
 			// Unknown rune for this token, unread and return
-			_ = t.reader.UnreadRune()
+			t.unreadRune(r)
 			break
 		}
 
@@ -175,7 +227,7 @@ func (t *lexer) Next() (Token, error) {
 			}
 
 			// Unknown rune for this token, unread and return
-			_ = t.reader.UnreadRune()
+			t.unreadRune(r)
 			break
 		}
 
@@ -184,7 +236,7 @@ func (t *lexer) Next() (Token, error) {
 		// Determine the token type
 		if token.Type == UnknownToken {
 			// maybe it's the beginning of a connect symbol, loop another time
-			if len(token.Value) == 1 && r == '-' {
+			if len(token.Value) == 1 && (r == '-' || r == '~') {
 				continue
 			}
 
@@ -215,3 +267,16 @@ func (t *lexer) Next() (Token, error) {
 
 	return token, nil
 }
+
+// unreadRune puts back the last rune read, and the position of the lexer along
+// with it.
+func (t *lexer) unreadRune(r rune) {
+	_ = t.reader.UnreadRune()
+
+	if r == '\n' {
+		t.line--
+		t.col = t.prevCol
+	} else {
+		t.col--
+	}
+}
@@ -1,8 +1,10 @@
 package ddl
 
 import (
+	"errors"
 	"fmt"
 	"github.com/sywesk/audiomix/pkg/audiograph"
+	"io"
 )
 
 var (
@@ -68,6 +70,11 @@ type ParameterStatement struct {
 	Line  int
 	Name  string
 	Value audiograph.Value
+
+	// Target is the optional connector following the value, as in:
+	//
+	//	@OUTPUT ch1 mixer:left
+	Target *Connector
 }
 
 func (p ParameterStatement) Type() StatementType {
@@ -79,6 +86,8 @@ type CreateComponentStatement struct {
 	VariableName  string
 	ComponentName string
 	Arguments     map[string]audiograph.Value
+	// ArgumentNames lists the arguments in the order they appear in the source
+	ArgumentNames []string
 }
 
 func (p CreateComponentStatement) Type() StatementType {
@@ -86,9 +95,10 @@ func (p CreateComponentStatement) Type() StatementType {
 }
 
 type ConnectStatement struct {
-	Line int
-	From Connector
-	To   Connector
+	Line     int
+	From     Connector
+	To       Connector
+	Feedback bool
 }
 
 func (p ConnectStatement) Type() StatementType {
@@ -119,7 +129,7 @@ func (p *parser) Next() (Statement, error) {
 	case AtToken:
 		return p.parseParameter()
 	case IdentifierToken:
-		secondToken, err := p.lexer.Next()
+		secondToken, err := p.nextToken()
 		if err != nil {
 			return nil, err
 		}
@@ -137,6 +147,9 @@ func (p *parser) Next() (Statement, error) {
 	}
 }
 
+// parseParameter parses parameter expressions that look like:
+//
+//	@<name> <value> [<componentName>:<connectorName>]
 func (p *parser) parseParameter() (Statement, error) {
 	token, err := p.getTypedToken(IdentifierToken)
 	if err != nil {
@@ -144,7 +157,7 @@ func (p *parser) parseParameter() (Statement, error) {
 	}
 	paramName := token.Value
 
-	valueToken, err := p.getOneOfTypedToken(IdentifierToken, NumberToken)
+	valueToken, err := p.getOneOfTypedToken(IdentifierToken, NumberToken, StringToken)
 	if err != nil {
 		return nil, err
 	}
@@ -154,31 +167,76 @@ func (p *parser) parseParameter() (Statement, error) {
 		return nil, err
 	}
 
-	return &ParameterStatement{
+	stmt := &ParameterStatement{
 		Line:  token.Line,
 		Name:  paramName,
 		Value: value,
-	}, nil
+	}
+
+	// The statement ends with the line, or the file
+	targetToken, err := p.lexer.Next()
+	if errors.Is(err, io.EOF) {
+		return stmt, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	switch targetToken.Type {
+	case ReturnToken:
+		return stmt, nil
+	case IdentifierToken:
+		tokens, err := p.getTypedTokens(ColonToken, IdentifierToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parameter target tokens: %w", err)
+		}
+
+		stmt.Target = &Connector{
+			VariableName:  targetToken.Value,
+			ConnectorName: tokens[1].Value,
+		}
+
+		return stmt, nil
+	default:
+		return nil, fmt.Errorf("unexpected token %s: %w", targetToken.String(), ErrSyntaxError)
+	}
 }
 
 // parseConnect parses connect expressions that look like:
 //
 //	<componentName>:<connectorName> -> <componentName>:<connectorName>
+//
+// or, for feedback connections delayed by one sample:
+//
+//	<componentName>:<connectorName> ~> <componentName>:<connectorName>
+//
+// Components on a loop closed by a feedback connection are processed one
+// sample at a time, which is slower than the block processing of the others.
 func (p *parser) parseConnect(token1 Token) (Statement, error) {
 	// Token1 is an Identifier
-	tokens, err := p.getTypedTokens(IdentifierToken, ConnectToken, IdentifierToken, ColonToken, IdentifierToken)
+	comp1ConnToken, err := p.getTypedToken(IdentifierToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connect tokens: %w", err)
+	}
+
+	connectToken, err := p.getOneOfTypedToken(ConnectToken, FeedbackConnectToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connect tokens: %w", err)
+	}
+
+	tokens, err := p.getTypedTokens(IdentifierToken, ColonToken, IdentifierToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get connect tokens: %w", err)
 	}
 
 	comp1Name := token1.Value
-	comp1Conn := tokens[0].Value
+	comp1Conn := comp1ConnToken.Value
 
-	comp2Name := tokens[2].Value
-	comp2Conn := tokens[4].Value
+	comp2Name := tokens[0].Value
+	comp2Conn := tokens[2].Value
 
 	return &ConnectStatement{
-		Line: token1.Line,
+		Line:     token1.Line,
+		Feedback: connectToken.Type == FeedbackConnectToken,
 		From: Connector{
 			VariableName:  comp1Name,
 			ConnectorName: comp1Conn,
@@ -221,16 +279,24 @@ func (p *parser) parseCreateComponent(token1 Token) (Statement, error) {
 			return nil, err
 		}
 
-		valueToken, err := p.getOneOfTypedToken(IdentifierToken, NumberToken)
+		valueToken, err := p.getOneOfTypedToken(IdentifierToken, NumberToken, StringToken, OpeningBracketToken)
 		if err != nil {
 			return nil, err
 		}
 
-		value, err := valueToken.ToValue()
+		var value audiograph.Value
+		if valueToken.Type == OpeningBracketToken {
+			value, err = p.parseFloatList()
+		} else {
+			value, err = valueToken.ToValue()
+		}
 		if err != nil {
 			return nil, err
 		}
 
+		if _, ok := stmt.Arguments[paramName]; !ok {
+			stmt.ArgumentNames = append(stmt.ArgumentNames, paramName)
+		}
 		stmt.Arguments[paramName] = value
 
 		token, err = p.getOneOfTypedToken(ComaToken, ClosingParenthesisToken)
@@ -241,11 +307,58 @@ func (p *parser) parseCreateComponent(token1 Token) (Statement, error) {
 		if token.Type == ClosingParenthesisToken {
 			break
 		}
+
+		// A coma is followed by the name of the next argument
+		token, err = p.getTypedToken(IdentifierToken)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return stmt, nil
 }
 
+// parseFloatList parses the numbers of a list, which may span several lines,
+// the opening bracket being already consumed:
+//
+//	[<number>, <number>, ...]
+func (p *parser) parseFloatList() (audiograph.Value, error) {
+	value := audiograph.Value{
+		Type: audiograph.FloatListValueType,
+	}
+
+	expectNumber := true
+	for {
+		token, err := p.nextToken()
+		if err != nil {
+			return value, err
+		}
+
+		switch {
+		case token.Type == ReturnToken:
+			continue
+		case token.Type == ClosingBracketToken && (!expectNumber || len(value.Floats) == 0):
+			return value, nil
+		case token.Type == NumberToken && expectNumber:
+			number, err := token.ToValue()
+			if err != nil {
+				return value, err
+			}
+
+			if number.Type == audiograph.IntegerValueType {
+				number.Float = float64(number.Integer)
+			}
+
+			value.Floats = append(value.Floats, number.Float)
+			expectNumber = false
+		case token.Type == ComaToken && !expectNumber:
+			expectNumber = true
+		default:
+			return value, fmt.Errorf("unexpected token %s in list: %w", token.String(), ErrSyntaxError)
+		}
+	}
+}
+
 func (p *parser) getTypedTokens(ts ...TokenType) ([]Token, error) {
 	var tokens []Token
 
@@ -263,7 +376,7 @@ func (p *parser) getTypedTokens(ts ...TokenType) ([]Token, error) {
 
 // getTypedToken gets the next token and ensures that it has the right type before returning it
 func (p *parser) getTypedToken(t TokenType) (Token, error) {
-	token, err := p.lexer.Next()
+	token, err := p.nextToken()
 	if err != nil {
 		return Token{}, err
 	}
@@ -276,7 +389,7 @@ func (p *parser) getTypedToken(t TokenType) (Token, error) {
 }
 
 func (p *parser) getOneOfTypedToken(ts ...TokenType) (Token, error) {
-	token, err := p.lexer.Next()
+	token, err := p.nextToken()
 	if err != nil {
 		return Token{}, err
 	}
@@ -291,6 +404,19 @@ func (p *parser) getOneOfTypedToken(ts ...TokenType) (Token, error) {
 	return Token{}, fmt.Errorf("unexpected token type '%s': %w", string(token.Type), ErrSyntaxError)
 }
 
+// nextToken gets the next token of a statement which has already started: the
+// end of the file is a syntax error at this point.
+func (p *parser) nextToken() (Token, error) {
+	token, err := p.lexer.Next()
+	if errors.Is(err, io.EOF) {
+		return Token{}, fmt.Errorf("unexpected end of file: %w", ErrSyntaxError)
+	} else if err != nil {
+		return Token{}, err
+	}
+
+	return token, nil
+}
+
 func (p *parser) getFirstUsefulToken() (Token, error) {
 	for {
 		token, err := p.lexer.Next()
@@ -2,18 +2,27 @@ package audiograph
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 )
 
 var (
 	ErrInputAlreadyUsed          = fmt.Errorf("input already used")
+	ErrCycleDetected             = fmt.Errorf("cable would create a cycle")
 	ErrUnknownCable              = fmt.Errorf("unknown cable")
 	ErrUnknownComponent          = fmt.Errorf("unknown component")
 	ErrUnknownComponentPort      = fmt.Errorf("unknown component port")
 	ErrUnknownComponentParameter = fmt.Errorf("unknown component parameter")
 	ErrInvalidValueType          = fmt.Errorf("invalid value type")
+	ErrInvalidChannelCount       = fmt.Errorf("invalid channel count")
+	ErrInvalidChannel            = fmt.Errorf("invalid channel")
+	ErrConnectedInputRemoved     = fmt.Errorf("connected input removed")
+	ErrPartialFrame              = fmt.Errorf("buffer does not hold a whole number of frames")
 )
 
+// maxBlockSize is the maximum number of frames processed at once by the graph.
+const maxBlockSize = 256
+
 type PortLocation int
 
 const (
@@ -36,15 +45,47 @@ type ComponentID uint64
 type Cable struct {
 	Source      PortAddress
 	Destination PortAddress
+
+	// Feedback cables deliver the value their source had at the previous
+	// iteration. They are the only way to build a loop in the graph, and are
+	// ignored when computing the execution order.
+	Feedback bool
 }
 
 type audioGraphComponent struct {
-	component   Component
-	description *ComponentDescription
-	deleted     bool
-	inputNames  map[string]uint
-	outputNames map[string]uint
-	paramNames  map[string]uint
+	component     BlockComponent
+	description   *ComponentDescription
+	deleted       bool
+	inputNames    map[string]uint
+	outputNames   map[string]uint
+	paramNames    map[string]uint
+	inputBuffers  [][]Value
+	inputDefaults []Value
+	outputBuffers [][]Value
+	// frameInputs and frameOutputs point into the buffers at the frame being
+	// processed, when the component is part of a feedback loop.
+	frameInputs  [][]Value
+	frameOutputs [][]Value
+	// creation is the number of components added to the graph before this
+	// one. Unlike the ID, it survives Compact, and seeds the component.
+	creation uint64
+}
+
+type channelSide int
+
+const (
+	bothSides channelSide = 0
+	leftSide  channelSide = 1
+	rightSide channelSide = 2
+)
+
+// outputChannel tells where the samples of a channel produced by Read come
+// from. Float ports feed the channel directly, while side selects which side
+// of a sample port is used: both sides are averaged by default.
+type outputChannel struct {
+	port PortAddress
+	side channelSide
+	set  bool
 }
 
 type audioGraphCable struct {
@@ -56,8 +97,15 @@ type AudioGraph struct {
 	mutex sync.RWMutex
 
 	samplingFrequency uint32
-	output            PortAddress
-	outputSet         bool
+	outputFormat      OutputFormat
+	channels          int
+	seed              int64
+
+	// createdComponents counts the components ever added to the graph.
+	createdComponents uint64
+
+	// outputs holds the source of each channel produced by Read, in order.
+	outputs []outputChannel
 
 	components       []audioGraphComponent
 	cables           []audioGraphCable
@@ -71,48 +119,109 @@ type AudioGraph struct {
 	// cableDestIndex allows to know which cable is connected to an input.
 	// There can be only **one** cable connected to a single input.
 	cableDestIndex map[PortAddress]CableID
+
+	// executionOrder lists the live components in the order they must be executed
+	// for every component to see the values produced by its sources during the
+	// same iteration. It is rebuilt each time a component or a cable changes.
+	executionOrder []ComponentID
+
+	// feedbackCables lists the live feedback cables. It is rebuilt along with
+	// executionOrder.
+	feedbackCables []CableID
+
+	// executionOrder[loopStart:loopEnd] holds the components closing a loop
+	// through feedback cables, which are processed one frame at a time. Every
+	// other component is processed a whole block at once.
+	loopStart int
+	loopEnd   int
 }
 
 func New() *AudioGraph {
 	return &AudioGraph{
+		outputFormat:     Int16OutputFormat,
 		cableSourceIndex: map[PortAddress][]CableID{},
 		cableDestIndex:   map[PortAddress]CableID{},
 	}
 }
 
+// AddComponent adds a per-sample component to the graph. Unless it also
+// implements BlockComponent, it is wrapped by a block adapter.
 func (a *AudioGraph) AddComponent(component Component) ComponentID {
+	if blockComponent, ok := component.(BlockComponent); ok {
+		return a.AddBlockComponent(blockComponent)
+	}
+
+	return a.AddBlockComponent(NewBlockAdapter(component))
+}
+
+func (a *AudioGraph) AddBlockComponent(component BlockComponent) ComponentID {
+	description := component.GetDescription()
+
 	inputNames := map[string]uint{}
-	for id, input := range component.GetDescription().Inputs {
+	for id, input := range description.Inputs {
 		inputNames[input.Name] = uint(id)
 	}
 
 	outputNames := map[string]uint{}
-	for id, output := range component.GetDescription().Outputs {
+	for id, output := range description.Outputs {
 		outputNames[output.Name] = uint(id)
 	}
 
 	paramNames := map[string]uint{}
-	for id, params := range component.GetDescription().Parameters {
+	for id, params := range description.Parameters {
 		paramNames[params.Name] = uint(id)
 	}
 
+	// Buffers start filled with the description values, so that inputs left
+	// unconnected keep their default value.
+	inputBuffers := make([][]Value, len(description.Inputs))
+	inputDefaults := make([]Value, len(description.Inputs))
+	for id, input := range description.Inputs {
+		inputBuffers[id] = newBuffer(input.Value)
+		inputDefaults[id] = input.Value
+	}
+
+	outputBuffers := make([][]Value, len(description.Outputs))
+	for id, output := range description.Outputs {
+		outputBuffers[id] = newBuffer(output.Value)
+	}
+
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
 	id := a.getNextComponentID()
 
 	a.components[id] = audioGraphComponent{
-		component:   component,
-		description: component.GetDescription(),
-		deleted:     false,
-		inputNames:  inputNames,
-		outputNames: outputNames,
-		paramNames:  paramNames,
+		component:     component,
+		description:   description,
+		deleted:       false,
+		inputNames:    inputNames,
+		outputNames:   outputNames,
+		paramNames:    paramNames,
+		inputBuffers:  inputBuffers,
+		inputDefaults: inputDefaults,
+		outputBuffers: outputBuffers,
+		frameInputs:   make([][]Value, len(inputBuffers)),
+		frameOutputs:  make([][]Value, len(outputBuffers)),
+		creation:      a.createdComponents,
 	}
+	a.createdComponents++
+	a.seedComponent(id)
+	a.prepareComponent(id)
+	a.updateExecutionOrder()
 
 	return id
 }
 
+func newBuffer(value Value) []Value {
+	buffer := make([]Value, maxBlockSize)
+	for i := range buffer {
+		buffer[i] = value
+	}
+
+	return buffer
+}
+
 func (a *AudioGraph) SetParameter(componentID ComponentID, paramName string, value Value) error {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
@@ -130,31 +239,316 @@ func (a *AudioGraph) SetParameter(componentID ComponentID, paramName string, val
 	if value.Type != component.description.Parameters[paramID].Value.Type {
 		return ErrInvalidValueType
 	}
-	value.CopyTo(&component.description.Parameters[paramID].Value)
+	parameter := &component.description.Parameters[paramID]
+	previous := parameter.Value
+	value.CopyTo(&parameter.Value)
+
+	if observer, ok := component.component.(ParameterObserver); ok {
+		err := observer.OnParameterChange(paramName)
+		if err != nil {
+			// The component rejected the value and kept its state, so does the
+			// description
+			previous.CopyTo(&parameter.Value)
+			return fmt.Errorf("failed to apply parameter '%s': %w", paramName, err)
+		}
+
+		// Cables are never dropped behind the caller's back: a value removing a
+		// connected input is rejected, and the previous one applied again
+		if name, ok := a.removedConnectedInput(componentID); ok {
+			previous.CopyTo(&parameter.Value)
+			_ = observer.OnParameterChange(paramName)
+
+			return fmt.Errorf("failed to apply parameter '%s': input '%s' is connected: %w", paramName, name, ErrConnectedInputRemoved)
+		}
+	}
+
+	a.prepareComponent(componentID)
+	a.refreshInputs(componentID)
 
 	return nil
 }
 
+// removedConnectedInput returns the name of a connected input missing from the
+// description of a component, whose inputs changed along with one of its
+// parameters. The caller must hold the mutex.
+func (a *AudioGraph) removedConnectedInput(id ComponentID) (string, bool) {
+	component := &a.components[id]
+
+	names := map[string]bool{}
+	for _, input := range component.description.Inputs {
+		names[input.Name] = true
+	}
+
+	for name, portID := range component.inputNames {
+		if _, connected := a.cableDestIndex[PortAddress{ComponentID: id, ConnectorID: portID}]; connected && !names[name] {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// refreshInputs updates the input ports of a component whose inputs changed
+// along with one of its parameters. Inputs are matched by name: they keep
+// their cable and their buffer, even when their index changes. Removed inputs
+// must not be connected. The caller must hold the mutex.
+func (a *AudioGraph) refreshInputs(id ComponentID) {
+	component := &a.components[id]
+	inputs := component.description.Inputs
+
+	changed := len(inputs) != len(component.inputNames)
+	for portID, input := range inputs {
+		if oldID, ok := component.inputNames[input.Name]; !ok || oldID != uint(portID) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return
+	}
+
+	inputNames := map[string]uint{}
+	for portID, input := range inputs {
+		inputNames[input.Name] = uint(portID)
+	}
+
+	// Cables are moved once every stale destination is out of the index, as a
+	// cable may move to the former port of another one
+	moved := map[CableID]uint{}
+	for name, oldID := range component.inputNames {
+		cableID, ok := a.cableDestIndex[PortAddress{ComponentID: id, ConnectorID: oldID}]
+		if !ok {
+			continue
+		}
+
+		newID := inputNames[name]
+		delete(a.cableDestIndex, a.cables[cableID].cable.Destination)
+		moved[cableID] = newID
+	}
+
+	for cableID, newID := range moved {
+		a.cables[cableID].cable.Destination.ConnectorID = newID
+		a.cableDestIndex[a.cables[cableID].cable.Destination] = cableID
+	}
+
+	inputBuffers := make([][]Value, len(inputs))
+	inputDefaults := make([]Value, len(inputs))
+	for portID, input := range inputs {
+		if oldID, ok := component.inputNames[input.Name]; ok {
+			inputBuffers[portID] = component.inputBuffers[oldID]
+			inputDefaults[portID] = component.inputDefaults[oldID]
+		} else {
+			inputBuffers[portID] = newBuffer(input.Value)
+			inputDefaults[portID] = input.Value
+		}
+	}
+
+	component.inputNames = inputNames
+	component.inputBuffers = inputBuffers
+	component.inputDefaults = inputDefaults
+	component.frameInputs = make([][]Value, len(inputBuffers))
+	a.updateExecutionOrder()
+}
+
+// SetOutput makes a single port the output of the graph, replacing any
+// output channel previously set. Sample ports feed their left and right sides
+// to the first two channels, float ports feed both channels.
 func (a *AudioGraph) SetOutput(outputComponentID ComponentID, outputPort string) error {
 	portAddr, err := a.ResolvePortAddr(outputComponentID, outputPort, OutputPortLocation)
 	if err != nil {
 		return fmt.Errorf("failed to resolve port addr: %w", err)
 	}
 
-	a.mutex.RLock()
-	defer a.mutex.RUnlock()
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	switch a.outputType(portAddr) {
+	case SampleValueType:
+		a.outputs = []outputChannel{
+			{port: portAddr, side: leftSide, set: true},
+			{port: portAddr, side: rightSide, set: true},
+		}
+	case FloatValueType:
+		a.outputs = []outputChannel{
+			{port: portAddr, side: bothSides, set: true},
+			{port: portAddr, side: bothSides, set: true},
+		}
+	default:
+		return fmt.Errorf("output port %s must be a float or a sample: %w", portAddr.String(), ErrInvalidValueType)
+	}
+
+	return nil
+}
 
-	a.output = portAddr
-	a.outputSet = true
+// SetChannelOutput makes a port the source of a single channel produced by
+// Read, channels being numbered from 0. Channels without a source are silent.
+// The port must be a float or a sample port, whose sides are then averaged.
+func (a *AudioGraph) SetChannelOutput(channel int, outputComponentID ComponentID, outputPort string) error {
+	if channel < 0 {
+		return ErrInvalidChannel
+	}
+
+	portAddr, err := a.ResolvePortAddr(outputComponentID, outputPort, OutputPortLocation)
+	if err != nil {
+		return fmt.Errorf("failed to resolve port addr: %w", err)
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	valueType := a.outputType(portAddr)
+	if valueType != SampleValueType && valueType != FloatValueType {
+		return fmt.Errorf("output port %s must be a float or a sample: %w", portAddr.String(), ErrInvalidValueType)
+	}
+
+	for len(a.outputs) <= channel {
+		a.outputs = append(a.outputs, outputChannel{})
+	}
+
+	a.outputs[channel] = outputChannel{
+		port: portAddr,
+		side: bothSides,
+		set:  true,
+	}
 
 	return nil
 }
 
+func (a *AudioGraph) outputType(portAddr PortAddress) ValueType {
+	return a.components[portAddr.ComponentID].description.Outputs[portAddr.ConnectorID].Value.Type
+}
+
 func (a *AudioGraph) SetSamplingFrequency(freq uint32) {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
+	if freq == a.samplingFrequency {
+		return
+	}
+
 	a.samplingFrequency = freq
+
+	for id, component := range a.components {
+		if !component.deleted {
+			a.prepareComponent(ComponentID(id))
+		}
+	}
+}
+
+// prepareComponent hands the sampling frequency to a Preparable component, once
+// it is known. The caller must hold the mutex.
+func (a *AudioGraph) prepareComponent(id ComponentID) {
+	preparable, ok := a.components[id].component.(Preparable)
+	if !ok || a.samplingFrequency == 0 {
+		return
+	}
+
+	preparable.Prepare(a.samplingFrequency)
+}
+
+func (a *AudioGraph) SamplingFrequency() uint32 {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.samplingFrequency
+}
+
+// SetSeed sets the seed from which every Seedable component is seeded, each
+// one getting its own seed derived from this one and the order in which it was
+// added, so that Compact does not change it. Graphs use 0 by default.
+func (a *AudioGraph) SetSeed(seed int64) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.seed = seed
+
+	for id, component := range a.components {
+		if !component.deleted {
+			a.seedComponent(ComponentID(id))
+		}
+	}
+}
+
+func (a *AudioGraph) seedComponent(id ComponentID) {
+	seedable, ok := a.components[id].component.(Seedable)
+	if !ok {
+		return
+	}
+
+	// splitmix64 finalizer, so that neighbour components get unrelated seeds
+	z := uint64(a.seed) + (a.components[id].creation+1)*0x9E3779B97F4A7C15
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	z ^= z >> 31
+
+	seedable.Seed(int64(z))
+}
+
+// SetOutputFormat sets the encoding of the samples produced by Read. Graphs
+// produce signed 16 bits samples by default.
+func (a *AudioGraph) SetOutputFormat(format OutputFormat) error {
+	if format.BytesPerSample() == 0 {
+		return ErrUnknownOutputFormat
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.outputFormat = format
+	return nil
+}
+
+func (a *AudioGraph) OutputFormat() OutputFormat {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.outputFormat
+}
+
+// SetChannels sets the number of interleaved channels produced by Read. By
+// default, graphs have as many channels as output channels, or two if no
+// output is set. Output channels beyond the channel count are dropped, except
+// for mono graphs which get the average of all the output channels.
+func (a *AudioGraph) SetChannels(channels int) error {
+	if channels < 1 {
+		return ErrInvalidChannelCount
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.channels = channels
+	return nil
+}
+
+func (a *AudioGraph) Channels() int {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.channelCount()
+}
+
+func (a *AudioGraph) channelCount() int {
+	if a.channels != 0 {
+		return a.channels
+	} else if len(a.outputs) > 0 {
+		return len(a.outputs)
+	}
+
+	return 2
+}
+
+// FrameSize returns the size, in bytes, of a frame produced by Read: one sample
+// for each channel.
+func (a *AudioGraph) FrameSize() int {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	return a.frameSize()
+}
+
+func (a *AudioGraph) frameSize() int {
+	return a.channelCount() * a.outputFormat.BytesPerSample()
 }
 
 func (a *AudioGraph) MustResolvePortAddr(componentID ComponentID, portName string, location PortLocation) PortAddress {
@@ -239,14 +633,24 @@ func (a *AudioGraph) DeleteComponent(id ComponentID) error {
 			continue
 		}
 
-		for _, cableID := range cableIDs {
+		// deleteCable edits the index in place, iterate over a copy
+		for _, cableID := range append([]CableID(nil), cableIDs...) {
 			a.deleteCable(cableID)
 		}
 	}
 
-	// Remove the component itself
-	a.components[id].deleted = true
+	// Channels provided by this component become silent
+	for i := range a.outputs {
+		if a.outputs[i].set && a.outputs[i].port.ComponentID == id {
+			a.outputs[i] = outputChannel{}
+		}
+	}
+
+	// Remove the component itself, dropping its buffers and the component so
+	// nothing stale can be reached through its ID until it is reused
+	a.components[id] = audioGraphComponent{deleted: true}
 	a.freeComponentIDs = append(a.freeComponentIDs, id)
+	a.updateExecutionOrder()
 
 	return nil
 }
@@ -254,13 +658,40 @@ func (a *AudioGraph) DeleteComponent(id ComponentID) error {
 func (a *AudioGraph) MustAddCable(sourceComponentID ComponentID, sourcePort string, destComponentID ComponentID, destPort string) CableID {
 	cableID, err := a.AddCable(sourceComponentID, sourcePort, destComponentID, destPort)
 	if err != nil {
-		panic(fmt.Sprintf("failed to add cable: %w", err))
+		panic(fmt.Sprintf("failed to add cable: %v", err))
 	}
 
 	return cableID
 }
 
+// AddCable connects an output to an input. The value produced by the source is
+// available to the destination during the same iteration, which is why such
+// cables cannot form a cycle: ErrCycleDetected is returned if they would.
 func (a *AudioGraph) AddCable(sourceComponentID ComponentID, sourcePort string, destComponentID ComponentID, destPort string) (CableID, error) {
+	return a.resolveAndAddCable(sourceComponentID, sourcePort, destComponentID, destPort, false)
+}
+
+func (a *AudioGraph) MustAddFeedbackCable(sourceComponentID ComponentID, sourcePort string, destComponentID ComponentID, destPort string) CableID {
+	cableID, err := a.AddFeedbackCable(sourceComponentID, sourcePort, destComponentID, destPort)
+	if err != nil {
+		panic(fmt.Sprintf("failed to add feedback cable: %v", err))
+	}
+
+	return cableID
+}
+
+// AddFeedbackCable connects an output to an input through a one sample delay:
+// the destination receives the value the source produced at the previous
+// iteration. Feedback cables may close loops in the graph.
+//
+// The components between the two ends of a feedback cable are processed one
+// frame at a time, which costs more than processing them by blocks. The rest
+// of the graph is not affected.
+func (a *AudioGraph) AddFeedbackCable(sourceComponentID ComponentID, sourcePort string, destComponentID ComponentID, destPort string) (CableID, error) {
+	return a.resolveAndAddCable(sourceComponentID, sourcePort, destComponentID, destPort, true)
+}
+
+func (a *AudioGraph) resolveAndAddCable(sourceComponentID ComponentID, sourcePort string, destComponentID ComponentID, destPort string, feedback bool) (CableID, error) {
 	sourcePortAddr, err := a.ResolvePortAddr(sourceComponentID, sourcePort, OutputPortLocation)
 	if err != nil {
 		return CableID(0), fmt.Errorf("failed to resolve source port addr: %w", err)
@@ -277,6 +708,7 @@ func (a *AudioGraph) AddCable(sourceComponentID ComponentID, sourcePort string,
 	return a.addCable(Cable{
 		Source:      sourcePortAddr,
 		Destination: destPortAddr,
+		Feedback:    feedback,
 	})
 }
 
@@ -285,6 +717,10 @@ func (a *AudioGraph) addCable(cable Cable) (CableID, error) {
 		return 0, fmt.Errorf("input %s cannot be used: %w", cable.Destination.String(), ErrInputAlreadyUsed)
 	}
 
+	if !cable.Feedback && a.isReachable(cable.Destination.ComponentID, cable.Source.ComponentID) {
+		return 0, fmt.Errorf("cannot connect %s to %s: %w", cable.Source.String(), cable.Destination.String(), ErrCycleDetected)
+	}
+
 	id := a.getNextCableID()
 
 	a.cables[id] = audioGraphCable{
@@ -293,10 +729,46 @@ func (a *AudioGraph) addCable(cable Cable) (CableID, error) {
 	}
 	a.cableDestIndex[cable.Destination] = id
 	a.cableSourceIndex[cable.Source] = append(a.cableSourceIndex[cable.Source], id)
+	a.updateExecutionOrder()
 
 	return id, nil
 }
 
+// isReachable tells whether values produced by the component from can reach the
+// component to by following regular (non feedback) cables.
+func (a *AudioGraph) isReachable(from ComponentID, to ComponentID) bool {
+	visited := map[ComponentID]bool{from: true}
+	stack := []ComponentID{from}
+
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if id == to {
+			return true
+		}
+
+		for portID := range a.components[id].description.Outputs {
+			cableIDs := a.cableSourceIndex[PortAddress{
+				ComponentID: id,
+				ConnectorID: uint(portID),
+			}]
+
+			for _, cableID := range cableIDs {
+				cable := a.cables[cableID].cable
+				if cable.Feedback || visited[cable.Destination.ComponentID] {
+					continue
+				}
+
+				visited[cable.Destination.ComponentID] = true
+				stack = append(stack, cable.Destination.ComponentID)
+			}
+		}
+	}
+
+	return false
+}
+
 func (a *AudioGraph) getNextCableID() CableID {
 	if len(a.freeCableIDs) > 0 {
 		nextID := a.freeCableIDs[0]
@@ -320,6 +792,8 @@ func (a *AudioGraph) DeleteCable(id CableID) error {
 	}
 
 	a.deleteCable(id)
+	a.updateExecutionOrder()
+
 	return nil
 }
 
@@ -337,41 +811,331 @@ func (a *AudioGraph) deleteCable(id CableID) {
 		a.cableSourceIndex[cable.Source] = sourceCables
 	}
 
-	// 3. Remove the actual cable
-	a.cables[id].deleted = true
+	// 3. The input falls back to its default value, unless its component is
+	// being deleted
+	destination := &a.components[cable.Destination.ComponentID]
+	if !destination.deleted {
+		buffer := destination.inputBuffers[cable.Destination.ConnectorID]
+		for i := range buffer {
+			buffer[i] = destination.inputDefaults[cable.Destination.ConnectorID]
+		}
+	}
+
+	// 4. Remove the actual cable
+	a.cables[id] = audioGraphCable{deleted: true}
 	a.freeCableIDs = append(a.freeCableIDs, id)
 }
 
-func (a *AudioGraph) iterate() error {
+// Compact rebuilds the component and cable tables without the slots left by
+// deleted components and cables, so that IDs are contiguous again and the free
+// ID lists are empty. IDs change in the process: the returned maps associate
+// the previous ID of every live component and cable with its new one.
+func (a *AudioGraph) Compact() (map[ComponentID]ComponentID, map[CableID]CableID) {
 	a.mutex.Lock()
 	defer a.mutex.Unlock()
 
-	// 1. Follow cables to copy values
-	for _, cable := range a.cables {
-		srcAddr := cable.cable.Source
-		dstAddr := cable.cable.Destination
+	// 1. Move live components to the front, in ID order
+	componentIDs := map[ComponentID]ComponentID{}
+	components := make([]audioGraphComponent, 0, len(a.components)-len(a.freeComponentIDs))
+	for id, component := range a.components {
+		if component.deleted {
+			continue
+		}
 
-		srcDesc := a.components[srcAddr.ComponentID].description
-		dstDesc := a.components[dstAddr.ComponentID].description
+		componentIDs[ComponentID(id)] = ComponentID(len(components))
+		components = append(components, component)
+	}
 
-		srcDesc.Outputs[srcAddr.ConnectorID].Value.CopyTo(&dstDesc.Inputs[dstAddr.ConnectorID].Value)
+	remapPort := func(p PortAddress) PortAddress {
+		return PortAddress{
+			ComponentID: componentIDs[p.ComponentID],
+			ConnectorID: p.ConnectorID,
+		}
 	}
 
-	// 2. Execute all components
+	// 2. Do the same for cables, pointing them to the new component IDs
+	cableIDs := map[CableID]CableID{}
+	cables := make([]audioGraphCable, 0, len(a.cables)-len(a.freeCableIDs))
+	cableSourceIndex := map[PortAddress][]CableID{}
+	cableDestIndex := map[PortAddress]CableID{}
+	for id, cable := range a.cables {
+		if cable.deleted {
+			continue
+		}
+
+		newID := CableID(len(cables))
+		cableIDs[CableID(id)] = newID
+
+		cable.cable.Source = remapPort(cable.cable.Source)
+		cable.cable.Destination = remapPort(cable.cable.Destination)
+		cables = append(cables, cable)
+
+		cableSourceIndex[cable.cable.Source] = append(cableSourceIndex[cable.cable.Source], newID)
+		cableDestIndex[cable.cable.Destination] = newID
+	}
+
+	// 3. Swap the tables
+	a.components = components
+	a.cables = cables
+	a.freeComponentIDs = nil
+	a.freeCableIDs = nil
+	a.cableSourceIndex = cableSourceIndex
+	a.cableDestIndex = cableDestIndex
+
+	for i := range a.outputs {
+		if a.outputs[i].set {
+			a.outputs[i].port = remapPort(a.outputs[i].port)
+		}
+	}
+
+	a.updateExecutionOrder()
+
+	return componentIDs, cableIDs
+}
+
+// updateExecutionOrder sorts the live components topologically, following
+// cableSourceIndex and cableDestIndex: a component always comes after every
+// component feeding one of its inputs through a regular cable. Feedback cables
+// are left out since they carry the value of the previous iteration. Ties are
+// broken by component ID so the order does not depend on map iteration.
+func (a *AudioGraph) updateExecutionOrder() {
+	// 1. Count, for each component, the regular cables arriving on its inputs
+	inDegrees := make([]int, len(a.components))
+	feedbackCables := make([]CableID, 0, len(a.feedbackCables))
+	for _, cableID := range a.cableDestIndex {
+		cable := a.cables[cableID].cable
+		if cable.Feedback {
+			feedbackCables = append(feedbackCables, cableID)
+			continue
+		}
+
+		inDegrees[cable.Destination.ComponentID]++
+	}
+	sort.Slice(feedbackCables, func(i, j int) bool { return feedbackCables[i] < feedbackCables[j] })
+	a.feedbackCables = feedbackCables
+
+	// 2. Start with components that do not depend on anything
+	order := make([]ComponentID, 0, len(a.components))
+	for id, component := range a.components {
+		if !component.deleted && inDegrees[id] == 0 {
+			order = append(order, ComponentID(id))
+		}
+	}
+
+	// 3. Walk the graph, releasing a component once all its sources are placed
+	for i := 0; i < len(order); i++ {
+		id := order[i]
+
+		for portID := range a.components[id].description.Outputs {
+			cableIDs := a.cableSourceIndex[PortAddress{
+				ComponentID: id,
+				ConnectorID: uint(portID),
+			}]
+
+			for _, cableID := range cableIDs {
+				if a.cables[cableID].cable.Feedback {
+					continue
+				}
+
+				destID := a.cables[cableID].cable.Destination.ComponentID
+
+				inDegrees[destID]--
+				if inDegrees[destID] == 0 {
+					order = append(order, destID)
+				}
+			}
+		}
+	}
+
+	a.executionOrder = a.groupFeedbackLoops(order)
+}
+
+// groupFeedbackLoops moves the components found on a path between the two
+// ends of a feedback cable together, and sets loopStart and loopEnd around
+// them. Their ancestors stay before them and every other component comes
+// after, so the order remains topological.
+func (a *AudioGraph) groupFeedbackLoops(order []ComponentID) []ComponentID {
+	ends := make([]bool, len(a.components))
+	for _, cableID := range a.feedbackCables {
+		cable := a.cables[cableID].cable
+		ends[cable.Source.ComponentID] = true
+		ends[cable.Destination.ComponentID] = true
+	}
+
+	// 1. Find the components reachable from a feedback cable end, and those
+	// reaching one, through regular cables
+	descendants := make([]bool, len(a.components))
+	ancestors := make([]bool, len(a.components))
+	for i := range order {
+		descendant, ancestor := order[i], order[len(order)-1-i]
+
+		descendants[descendant] = descendants[descendant] || ends[descendant]
+		ancestors[ancestor] = ancestors[ancestor] || ends[ancestor]
+
+		a.forEachRegularCable(descendant, func(cable Cable) {
+			if descendants[descendant] {
+				descendants[cable.Destination.ComponentID] = true
+			}
+		})
+		a.forEachRegularCable(ancestor, func(cable Cable) {
+			if ancestors[cable.Destination.ComponentID] {
+				ancestors[ancestor] = true
+			}
+		})
+	}
+
+	// 2. Components both reachable from an end and reaching one make the loops
+	grouped := make([]ComponentID, 0, len(order))
+	var loop, after []ComponentID
+	for _, id := range order {
+		switch {
+		case descendants[id] && ancestors[id]:
+			loop = append(loop, id)
+		case ancestors[id]:
+			grouped = append(grouped, id)
+		default:
+			after = append(after, id)
+		}
+	}
+
+	a.loopStart = len(grouped)
+	a.loopEnd = len(grouped) + len(loop)
+
+	grouped = append(grouped, loop...)
+	return append(grouped, after...)
+}
+
+// forEachRegularCable calls fn for each regular cable leaving the component.
+func (a *AudioGraph) forEachRegularCable(id ComponentID, fn func(cable Cable)) {
+	for portID := range a.components[id].description.Outputs {
+		cableIDs := a.cableSourceIndex[PortAddress{
+			ComponentID: id,
+			ConnectorID: uint(portID),
+		}]
+
+		for _, cableID := range cableIDs {
+			if cable := a.cables[cableID].cable; !cable.Feedback {
+				fn(cable)
+			}
+		}
+	}
+}
+
+// processBlock runs the graph over the given number of frames. The caller must
+// hold the mutex.
+func (a *AudioGraph) processBlock(frames int) error {
 	ctx := ExecutionContext{
 		SamplingFrequency: a.samplingFrequency,
 	}
 
-	for id, component := range a.components {
-		err := component.component.Execute(ctx)
-		if err != nil {
-			return fmt.Errorf("failed to execute component %d: %w", id, err)
+	// 1. Components feeding the feedback loops, by block
+	for _, id := range a.executionOrder[:a.loopStart] {
+		if err := a.processComponent(ctx, id, frames); err != nil {
+			return err
+		}
+	}
+
+	// 2. The feedback loops, one frame at a time
+	if err := a.processFeedbackLoops(ctx, frames); err != nil {
+		return err
+	}
+
+	// 3. Everything else, by block
+	for _, id := range a.executionOrder[a.loopEnd:] {
+		if err := a.processComponent(ctx, id, frames); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// processComponent runs a component over the whole block. All its sources
+// have already been processed.
+func (a *AudioGraph) processComponent(ctx ExecutionContext, id ComponentID, frames int) error {
+	component := &a.components[id]
+
+	err := component.component.ProcessBlock(BlockContext{
+		ExecutionContext: ctx,
+		Inputs:           component.inputBuffers,
+		Outputs:          component.outputBuffers,
+	}, frames)
+	if err != nil {
+		return fmt.Errorf("failed to execute component %d: %w", id, err)
+	}
+
+	// Follow its cables to hand the new values to the components downstream
+	a.propagateOutputs(id, 0, frames)
+
+	return nil
+}
+
+// processFeedbackLoops runs the components of the feedback loops frame by
+// frame, so that feedback cables deliver the value of the previous frame.
+func (a *AudioGraph) processFeedbackLoops(ctx ExecutionContext, frames int) error {
+	loop := a.executionOrder[a.loopStart:a.loopEnd]
+	if len(loop) == 0 {
+		return nil
+	}
+
+	for frame := 0; frame < frames; frame++ {
+		// The first frame receives the last value of the previous block, which
+		// was delivered at the end of it
+		if frame > 0 {
+			for _, cableID := range a.feedbackCables {
+				a.copyFeedbackValue(a.cables[cableID].cable, frame-1, frame)
+			}
+		}
+
+		for _, id := range loop {
+			component := &a.components[id]
+			for portID, buffer := range component.inputBuffers {
+				component.frameInputs[portID] = buffer[frame:]
+			}
+			for portID, buffer := range component.outputBuffers {
+				component.frameOutputs[portID] = buffer[frame:]
+			}
+
+			err := component.component.ProcessBlock(BlockContext{
+				ExecutionContext: ctx,
+				Inputs:           component.frameInputs,
+				Outputs:          component.frameOutputs,
+			}, 1)
+			if err != nil {
+				return fmt.Errorf("failed to execute component %d: %w", id, err)
+			}
+
+			a.propagateOutputs(id, frame, 1)
 		}
 	}
 
+	for _, cableID := range a.feedbackCables {
+		a.copyFeedbackValue(a.cables[cableID].cable, frames-1, 0)
+	}
+
 	return nil
 }
 
+// propagateOutputs copies the output buffers of a component to every input
+// connected to it through a regular cable, starting at the given frame.
+func (a *AudioGraph) propagateOutputs(id ComponentID, offset int, frames int) {
+	a.forEachRegularCable(id, func(cable Cable) {
+		src := a.components[cable.Source.ComponentID].outputBuffers[cable.Source.ConnectorID]
+		dst := a.components[cable.Destination.ComponentID].inputBuffers[cable.Destination.ConnectorID]
+
+		copy(dst[offset:offset+frames], src[offset:offset+frames])
+	})
+}
+
+// copyFeedbackValue hands the value a feedback cable's source produced at a
+// frame to its destination, at another frame.
+func (a *AudioGraph) copyFeedbackValue(cable Cable, srcFrame int, dstFrame int) {
+	src := a.components[cable.Source.ComponentID].outputBuffers[cable.Source.ConnectorID]
+	dst := a.components[cable.Destination.ComponentID].inputBuffers[cable.Destination.ConnectorID]
+
+	dst[dstFrame] = src[srcFrame]
+}
+
 func filterOutCableID(ids []CableID, id CableID) []CableID {
 	if len(ids) == 0 {
 		return nil
@@ -389,45 +1153,88 @@ func filterOutCableID(ids []CableID, id CableID) []CableID {
 	return ids
 }
 
+// Read renders the graph into p, as interleaved frames of the configured
+// output format and channel count. p must hold a whole number of frames,
+// otherwise ErrPartialFrame is returned.
 func (a *AudioGraph) Read(p []byte) (n int, err error) {
-	// 4 bytes, 2 for the left channel, 2 for the Right
-	sampleSize := 4
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	frameSize := a.frameSize()
+	sampleSize := a.outputFormat.BytesPerSample()
 
-	if len(p)%sampleSize != 0 {
-		panic("audioProcuder.Read: p is not a multiple of sampleSize")
+	if len(p)%frameSize != 0 {
+		return 0, fmt.Errorf("%d bytes for frames of %d bytes: %w", len(p), frameSize, ErrPartialFrame)
 	}
 
-	requestedSamples := len(p) / sampleSize
+	requestedFrames := len(p) / frameSize
 
-	if requestedSamples > 500 {
-		requestedSamples = 500
+	if requestedFrames > 500 {
+		requestedFrames = 500
 	}
 
-	for i := 0; i < requestedSamples; i++ {
-		err := a.iterate()
+	for offset := 0; offset < requestedFrames; offset += maxBlockSize {
+		frames := requestedFrames - offset
+		if frames > maxBlockSize {
+			frames = maxBlockSize
+		}
+
+		err := a.processBlock(frames)
 		if err != nil {
-			return 0, fmt.Errorf("failed to compute iteration: %w", err)
+			return offset * frameSize, fmt.Errorf("failed to compute iteration: %w", err)
 		}
 
-		left := int16(0)
-		right := int16(0)
+		for i := 0; i < frames; i++ {
+			frame := p[(offset+i)*frameSize : (offset+i+1)*frameSize]
+			a.encodeFrame(i, frame, sampleSize)
+		}
+	}
 
-		if !a.outputSet {
-			left = 0
-			right = 0
-		} else {
-			component := a.components[a.output.ComponentID]
-			value := component.description.Outputs[a.output.ConnectorID].Value
+	return requestedFrames * frameSize, nil
+}
+
+// encodeFrame writes the values of the output channels for the given frame of
+// the last processed block.
+func (a *AudioGraph) encodeFrame(frame int, dst []byte, sampleSize int) {
+	channels := a.channelCount()
+
+	// Mono downmix
+	if channels == 1 && len(a.outputs) > 1 {
+		sum := SampleType(0)
+		for _, output := range a.outputs {
+			sum += a.channelValue(output, frame)
+		}
 
-			left = int16(value.Sample.Left)
-			right = int16(value.Sample.Right)
+		a.outputFormat.encode(sum/SampleType(len(a.outputs)), dst)
+		return
+	}
+
+	for channel := 0; channel < channels; channel++ {
+		value := SampleType(0)
+		if channel < len(a.outputs) {
+			value = a.channelValue(a.outputs[channel], frame)
 		}
 
-		p[i*sampleSize+0] = byte(left >> 0)
-		p[i*sampleSize+1] = byte(left >> 8)
-		p[i*sampleSize+2] = byte(right >> 0)
-		p[i*sampleSize+3] = byte(right >> 8)
+		a.outputFormat.encode(value, dst[channel*sampleSize:])
+	}
+}
+
+func (a *AudioGraph) channelValue(output outputChannel, frame int) SampleType {
+	if !output.set {
+		return 0
+	}
+
+	value := a.components[output.port.ComponentID].outputBuffers[output.port.ConnectorID][frame]
+	if value.Type == FloatValueType {
+		return SampleType(value.Float)
+	}
+
+	switch output.side {
+	case leftSide:
+		return value.Sample.Left
+	case rightSide:
+		return value.Sample.Right
 	}
 
-	return requestedSamples * sampleSize, nil
+	return (value.Sample.Left + value.Sample.Right) / 2
 }
@@ -0,0 +1,553 @@
+package audiograph
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sync/atomic"
+	"testing"
+)
+
+var errExecutedAfterDelete = errors.New("component executed after being deleted")
+
+// testComponent outputs the sum of its inputs plus a constant. It fails when
+// executed once the test flagged it as deleted.
+type testComponent struct {
+	description ComponentDescription
+	constant    float64
+	deleted     atomic.Bool
+}
+
+func newTestComponent(constant float64, inputs ...string) *testComponent {
+	component := &testComponent{
+		description: ComponentDescription{
+			Outputs: []ComponentOutput{
+				{Name: "out", Value: Value{Type: FloatValueType}},
+			},
+		},
+		constant: constant,
+	}
+
+	for _, name := range inputs {
+		component.description.Inputs = append(component.description.Inputs, ComponentInput{
+			Name:  name,
+			Value: Value{Type: FloatValueType},
+		})
+	}
+
+	return component
+}
+
+func (c *testComponent) GetDescription() *ComponentDescription {
+	return &c.description
+}
+
+func (c *testComponent) Execute(ctx ExecutionContext) error {
+	if c.deleted.Load() {
+		return errExecutedAfterDelete
+	}
+
+	sum := c.constant
+	for _, input := range c.description.Inputs {
+		sum += input.Value.Float
+	}
+
+	c.description.Outputs[0].Value.Float = sum
+	return nil
+}
+
+func newTestGraph(t *testing.T) *AudioGraph {
+	t.Helper()
+
+	graph := New()
+	graph.SetSamplingFrequency(48000)
+	if err := graph.SetOutputFormat(Float32OutputFormat); err != nil {
+		t.Fatalf("SetOutputFormat: %v", err)
+	}
+
+	return graph
+}
+
+func deleteTestComponent(t *testing.T, graph *AudioGraph, id ComponentID, component *testComponent) {
+	t.Helper()
+
+	if err := graph.DeleteComponent(id); err != nil {
+		t.Fatalf("DeleteComponent(%d): %v", id, err)
+	}
+	component.deleted.Store(true)
+}
+
+// readFrames pulls frames through Read and returns the first channel.
+func readFrames(t *testing.T, graph *AudioGraph, frames int) []float32 {
+	t.Helper()
+
+	samples, err := tryReadFrames(graph, frames)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+
+	return samples
+}
+
+func tryReadFrames(graph *AudioGraph, frames int) ([]float32, error) {
+	frameSize := graph.FrameSize()
+	buffer := make([]byte, frames*frameSize)
+
+	n, err := graph.Read(buffer)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make([]float32, n/frameSize)
+	for i := range samples {
+		samples[i] = math.Float32frombits(binary.LittleEndian.Uint32(buffer[i*frameSize:]))
+	}
+
+	return samples, nil
+}
+
+func expectSamples(t *testing.T, samples []float32, expected float32) {
+	t.Helper()
+
+	for i, sample := range samples {
+		if sample != expected {
+			t.Fatalf("sample %d: got %v, expected %v", i, sample, expected)
+		}
+	}
+}
+
+// checkCableIndexes verifies that the cable indexes reference exactly the live
+// cables.
+func checkCableIndexes(t *testing.T, graph *AudioGraph) {
+	t.Helper()
+
+	live := 0
+	for id, cable := range graph.cables {
+		if cable.deleted {
+			continue
+		}
+		live++
+
+		if destID, ok := graph.cableDestIndex[cable.cable.Destination]; !ok || destID != CableID(id) {
+			t.Errorf("cable %d: destination index holds %d, %v", id, destID, ok)
+		}
+
+		found := false
+		for _, sourceID := range graph.cableSourceIndex[cable.cable.Source] {
+			found = found || sourceID == CableID(id)
+		}
+		if !found {
+			t.Errorf("cable %d: missing from the source index", id)
+		}
+
+		for _, port := range []PortAddress{cable.cable.Source, cable.cable.Destination} {
+			if graph.components[port.ComponentID].deleted {
+				t.Errorf("cable %d: connected to deleted component %d", id, port.ComponentID)
+			}
+		}
+	}
+
+	if len(graph.cableDestIndex) != live {
+		t.Errorf("destination index holds %d cables, expected %d", len(graph.cableDestIndex), live)
+	}
+
+	sources := 0
+	for _, ids := range graph.cableSourceIndex {
+		sources += len(ids)
+	}
+	if sources != live {
+		t.Errorf("source index holds %d cables, expected %d", sources, live)
+	}
+}
+
+func TestDeleteComponentWhileReading(t *testing.T) {
+	graph := newTestGraph(t)
+
+	first := newTestComponent(0.25)
+	second := newTestComponent(0.5)
+	sum := newTestComponent(0, "a", "b")
+
+	firstID := graph.AddComponent(first)
+	secondID := graph.AddComponent(second)
+	sumID := graph.AddComponent(sum)
+
+	graph.MustAddCable(firstID, "out", sumID, "a")
+	graph.MustAddCable(secondID, "out", sumID, "b")
+	if err := graph.SetOutput(sumID, "out"); err != nil {
+		t.Fatalf("SetOutput: %v", err)
+	}
+
+	expectSamples(t, readFrames(t, graph, 300), 0.75)
+
+	started := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		defer close(done)
+
+		for i := 0; i < 200; i++ {
+			samples, err := tryReadFrames(graph, 300)
+			if err != nil {
+				done <- err
+				return
+			}
+
+			// A single Read never sees the graph half modified
+			for _, sample := range samples {
+				if sample != samples[0] || (sample != 0.75 && sample != 0.25) {
+					done <- errors.New("unexpected sample")
+					return
+				}
+			}
+
+			if i == 0 {
+				close(started)
+			}
+		}
+	}()
+
+	// The reader may fail before it starts
+	select {
+	case <-started:
+	case err := <-done:
+		t.Fatalf("reading before deleting: %v", err)
+	}
+	deleteTestComponent(t, graph, secondID, second)
+
+	if err := <-done; err != nil {
+		t.Fatalf("reading while deleting: %v", err)
+	}
+
+	// The disconnected input is back to its default value
+	expectSamples(t, readFrames(t, graph, 300), 0.25)
+	checkCableIndexes(t, graph)
+}
+
+func TestReAddIntoFreedSlot(t *testing.T) {
+	graph := newTestGraph(t)
+
+	old := newTestComponent(0.5)
+	oldID := graph.AddComponent(old)
+	if err := graph.SetOutput(oldID, "out"); err != nil {
+		t.Fatalf("SetOutput: %v", err)
+	}
+
+	expectSamples(t, readFrames(t, graph, 100), 0.5)
+
+	deleteTestComponent(t, graph, oldID, old)
+
+	// The channel fed by the deleted component is silent
+	expectSamples(t, readFrames(t, graph, 100), 0)
+
+	replacement := newTestComponent(0.125, "in")
+	replacementID := graph.AddComponent(replacement)
+	if replacementID != oldID {
+		t.Fatalf("re-added component got ID %d, expected the freed %d", replacementID, oldID)
+	}
+
+	source := newTestComponent(0.25)
+	sourceID := graph.AddComponent(source)
+	graph.MustAddCable(sourceID, "out", replacementID, "in")
+	if err := graph.SetOutput(replacementID, "out"); err != nil {
+		t.Fatalf("SetOutput: %v", err)
+	}
+
+	expectSamples(t, readFrames(t, graph, 300), 0.375)
+	checkCableIndexes(t, graph)
+
+	if _, err := graph.ResolvePortAddr(replacementID, "in", InputPortLocation); err != nil {
+		t.Errorf("input of the re-added component: %v", err)
+	}
+}
+
+func TestDeleteCableEndpoint(t *testing.T) {
+	graph := newTestGraph(t)
+
+	source := newTestComponent(0.5)
+	middle := newTestComponent(0.25, "in")
+	sink := newTestComponent(0, "in")
+
+	sourceID := graph.AddComponent(source)
+	middleID := graph.AddComponent(middle)
+	sinkID := graph.AddComponent(sink)
+
+	inCable := graph.MustAddCable(sourceID, "out", middleID, "in")
+	outCable := graph.MustAddCable(middleID, "out", sinkID, "in")
+	if err := graph.SetOutput(sinkID, "out"); err != nil {
+		t.Fatalf("SetOutput: %v", err)
+	}
+
+	expectSamples(t, readFrames(t, graph, 300), 0.75)
+
+	// Deleting the component both cables end on removes them
+	deleteTestComponent(t, graph, middleID, middle)
+
+	for _, cableID := range []CableID{inCable, outCable} {
+		if err := graph.DeleteCable(cableID); !errors.Is(err, ErrUnknownCable) {
+			t.Errorf("DeleteCable(%d): got %v, expected %v", cableID, err, ErrUnknownCable)
+		}
+	}
+
+	if ids := graph.cableSourceIndex[PortAddress{ComponentID: sourceID}]; len(ids) != 0 {
+		t.Errorf("source still indexes cables %v", ids)
+	}
+
+	expectSamples(t, readFrames(t, graph, 300), 0)
+	checkCableIndexes(t, graph)
+
+	// The freed cable IDs are reused
+	cableID := graph.MustAddCable(sourceID, "out", sinkID, "in")
+	if cableID != inCable && cableID != outCable {
+		t.Errorf("new cable got ID %d, expected a freed one", cableID)
+	}
+
+	expectSamples(t, readFrames(t, graph, 300), 0.5)
+	checkCableIndexes(t, graph)
+}
+
+func TestCompactBetweenReads(t *testing.T) {
+	graph := newTestGraph(t)
+
+	components := make([]*testComponent, 6)
+	ids := make([]ComponentID, len(components))
+	for i := range components {
+		components[i] = newTestComponent(float64(i+1)/64, "in")
+		ids[i] = graph.AddComponent(components[i])
+	}
+
+	// A chain going through every component
+	cables := make([]CableID, len(components)-1)
+	for i := range cables {
+		cables[i] = graph.MustAddCable(ids[i], "out", ids[i+1], "in")
+	}
+	if err := graph.SetOutput(ids[5], "out"); err != nil {
+		t.Fatalf("SetOutput: %v", err)
+	}
+
+	expectSamples(t, readFrames(t, graph, 300), 21.0/64)
+
+	// Leaves holes: components 1 and 3, along with the cables 0 to 3
+	deleteTestComponent(t, graph, ids[1], components[1])
+	deleteTestComponent(t, graph, ids[3], components[3])
+	bridge := graph.MustAddCable(ids[2], "out", ids[4], "in")
+
+	// 3 + 5 + 6
+	expectSamples(t, readFrames(t, graph, 300), 14.0/64)
+
+	componentIDs, cableIDs := graph.Compact()
+
+	expectedComponents := map[ComponentID]ComponentID{ids[0]: 0, ids[2]: 1, ids[4]: 2, ids[5]: 3}
+	if len(componentIDs) != len(expectedComponents) {
+		t.Errorf("component map %v, expected %v", componentIDs, expectedComponents)
+	}
+	for oldID, newID := range expectedComponents {
+		if componentIDs[oldID] != newID {
+			t.Errorf("component %d moved to %d, expected %d", oldID, componentIDs[oldID], newID)
+		}
+	}
+
+	// Cable 4 (component 4 to 5) and the bridge, which reused a freed ID
+	if len(cableIDs) != 2 {
+		t.Errorf("cable map %v, expected 2 cables", cableIDs)
+	}
+	if _, ok := cableIDs[cables[4]]; !ok {
+		t.Errorf("cable %d missing from %v", cables[4], cableIDs)
+	}
+	if _, ok := cableIDs[bridge]; !ok {
+		t.Errorf("cable %d missing from %v", bridge, cableIDs)
+	}
+	for _, newID := range cableIDs {
+		if newID >= CableID(len(cableIDs)) {
+			t.Errorf("cable IDs are not contiguous: %v", cableIDs)
+		}
+	}
+
+	if len(graph.components) != 4 || len(graph.freeComponentIDs) != 0 || len(graph.freeCableIDs) != 0 {
+		t.Errorf("tables not compacted: %d components, %d free components, %d free cables",
+			len(graph.components), len(graph.freeComponentIDs), len(graph.freeCableIDs))
+	}
+
+	checkCableIndexes(t, graph)
+	expectSamples(t, readFrames(t, graph, 300), 14.0/64)
+
+	// The graph keeps working with the new IDs
+	deleteTestComponent(t, graph, componentIDs[ids[2]], components[2])
+	expectSamples(t, readFrames(t, graph, 300), 11.0/64)
+	checkCableIndexes(t, graph)
+}
+
+func TestFeedbackLoopDelaysBySample(t *testing.T) {
+	graph := newTestGraph(t)
+
+	source := newTestComponent(0.5)
+	accumulator := newTestComponent(0, "in", "feedback")
+	sink := newTestComponent(0, "in")
+	unrelated := newTestComponent(1)
+
+	sourceID := graph.AddComponent(source)
+	accumulatorID := graph.AddComponent(accumulator)
+	sinkID := graph.AddComponent(sink)
+	unrelatedID := graph.AddComponent(unrelated)
+
+	graph.MustAddCable(sourceID, "out", accumulatorID, "in")
+	graph.MustAddFeedbackCable(accumulatorID, "out", accumulatorID, "feedback")
+	graph.MustAddCable(accumulatorID, "out", sinkID, "in")
+	if err := graph.SetOutput(sinkID, "out"); err != nil {
+		t.Fatalf("SetOutput: %v", err)
+	}
+
+	// Only the accumulator is processed one frame at a time
+	loop := graph.executionOrder[graph.loopStart:graph.loopEnd]
+	if len(loop) != 1 || loop[0] != accumulatorID {
+		t.Errorf("feedback loop holds %v, expected [%d]", loop, accumulatorID)
+	}
+	for _, id := range graph.executionOrder[:graph.loopStart] {
+		if id != sourceID {
+			t.Errorf("component %d processed before the loop", id)
+		}
+	}
+	if len(graph.executionOrder) != 4 {
+		t.Errorf("execution order %v, expected 4 components", graph.executionOrder)
+	}
+
+	// The accumulator sees its previous output at each frame, across blocks
+	expected := float32(0)
+	for i := 0; i < 3; i++ {
+		for j, sample := range readFrames(t, graph, 300) {
+			expected += 0.5
+			if sample != expected {
+				t.Fatalf("read %d, sample %d: got %v, expected %v", i, j, sample, expected)
+			}
+		}
+	}
+
+	if err := graph.SetOutput(unrelatedID, "out"); err != nil {
+		t.Fatalf("SetOutput: %v", err)
+	}
+	expectSamples(t, readFrames(t, graph, 300), 1)
+}
+
+var errRejectedParameter = errors.New("rejected parameter")
+
+// rejectingComponent only accepts positive values for its parameter.
+type rejectingComponent struct {
+	*testComponent
+}
+
+func (c *rejectingComponent) OnParameterChange(name string) error {
+	if c.description.Parameters[0].Value.Integer <= 0 {
+		return errRejectedParameter
+	}
+
+	return nil
+}
+
+func TestSetParameterRestoresRejectedValue(t *testing.T) {
+	graph := newTestGraph(t)
+
+	component := &rejectingComponent{testComponent: newTestComponent(0)}
+	component.description.Parameters = []ComponentParameter{
+		{Name: "size", Value: Value{Type: IntegerValueType, Integer: 4}},
+	}
+	id := graph.AddComponent(component)
+
+	err := graph.SetParameter(id, "size", Value{Type: IntegerValueType, Integer: -1})
+	if !errors.Is(err, errRejectedParameter) {
+		t.Fatalf("SetParameter: got %v, expected %v", err, errRejectedParameter)
+	}
+
+	if size := component.description.Parameters[0].Value.Integer; size != 4 {
+		t.Errorf("parameter holds %d after being rejected, expected 4", size)
+	}
+}
+
+func TestReadPartialFrame(t *testing.T) {
+	graph := newTestGraph(t)
+	if err := graph.SetChannels(2); err != nil {
+		t.Fatalf("SetChannels: %v", err)
+	}
+
+	n, err := graph.Read(make([]byte, graph.FrameSize()+1))
+	if n != 0 || !errors.Is(err, ErrPartialFrame) {
+		t.Errorf("Read: got %d, %v, expected 0, %v", n, err, ErrPartialFrame)
+	}
+}
+
+// seedableComponent records the seed it was given.
+type seedableComponent struct {
+	*testComponent
+	seed int64
+}
+
+func (c *seedableComponent) Seed(seed int64) {
+	c.seed = seed
+}
+
+func TestCompactKeepsSeeds(t *testing.T) {
+	graph := newTestGraph(t)
+	graph.SetSeed(42)
+
+	removed := newTestComponent(0)
+	removedID := graph.AddComponent(removed)
+	component := &seedableComponent{testComponent: newTestComponent(0)}
+	graph.AddComponent(component)
+
+	deleteTestComponent(t, graph, removedID, removed)
+	graph.Compact()
+
+	seed := component.seed
+	graph.SetSeed(42)
+	if component.seed != seed {
+		t.Errorf("seed changed from %d to %d after Compact", seed, component.seed)
+	}
+}
+
+// resizableComponent has as many inputs as its count parameter tells.
+type resizableComponent struct {
+	*testComponent
+}
+
+func (c *resizableComponent) OnParameterChange(name string) error {
+	c.description.Inputs = nil
+	for i := int64(0); i < c.description.Parameters[0].Value.Integer; i++ {
+		c.description.Inputs = append(c.description.Inputs, ComponentInput{
+			Name:  string(rune('a' + i)),
+			Value: Value{Type: FloatValueType},
+		})
+	}
+
+	return nil
+}
+
+func TestSetParameterKeepsConnectedInputs(t *testing.T) {
+	graph := newTestGraph(t)
+
+	component := &resizableComponent{testComponent: newTestComponent(0, "a", "b")}
+	component.description.Parameters = []ComponentParameter{
+		{Name: "count", Value: Value{Type: IntegerValueType, Integer: 2}},
+	}
+	id := graph.AddComponent(component)
+	sourceID := graph.AddComponent(newTestComponent(0.5))
+	cableID := graph.MustAddCable(sourceID, "out", id, "b")
+	if err := graph.SetOutput(id, "out"); err != nil {
+		t.Fatalf("SetOutput: %v", err)
+	}
+
+	err := graph.SetParameter(id, "count", Value{Type: IntegerValueType, Integer: 1})
+	if !errors.Is(err, ErrConnectedInputRemoved) {
+		t.Fatalf("SetParameter: got %v, expected %v", err, ErrConnectedInputRemoved)
+	}
+
+	if count := component.description.Parameters[0].Value.Integer; count != 2 || len(component.description.Inputs) != 2 {
+		t.Errorf("count %d and %d inputs after the change was rejected, expected 2", count, len(component.description.Inputs))
+	}
+	if err := graph.DeleteCable(cableID); err != nil {
+		t.Errorf("cable of the connected input: %v", err)
+	}
+
+	// Unconnected inputs may be removed
+	if err := graph.SetParameter(id, "count", Value{Type: IntegerValueType, Integer: 1}); err != nil {
+		t.Errorf("SetParameter: %v", err)
+	}
+	expectSamples(t, readFrames(t, graph, 100), 0)
+	checkCableIndexes(t, graph)
+}
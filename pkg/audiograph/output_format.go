@@ -0,0 +1,93 @@
+package audiograph
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+)
+
+var (
+	ErrUnknownOutputFormat = fmt.Errorf("unknown output format")
+)
+
+// OutputFormat is the encoding of the samples produced by AudioGraph.Read. All
+// formats are little endian.
+type OutputFormat int
+
+const (
+	Int16OutputFormat   OutputFormat = 1
+	Int24OutputFormat   OutputFormat = 2
+	Int32OutputFormat   OutputFormat = 3
+	Float32OutputFormat OutputFormat = 4
+)
+
+var (
+	outputFormatNames = map[string]OutputFormat{
+		"int16":   Int16OutputFormat,
+		"int24":   Int24OutputFormat,
+		"int32":   Int32OutputFormat,
+		"float32": Float32OutputFormat,
+	}
+)
+
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	format, ok := outputFormatNames[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("output format '%s': %w", name, ErrUnknownOutputFormat)
+	}
+
+	return format, nil
+}
+
+func (f OutputFormat) String() string {
+	for name, format := range outputFormatNames {
+		if format == f {
+			return name
+		}
+	}
+
+	return fmt.Sprintf("unknown(%d)", int(f))
+}
+
+// BytesPerSample returns the size of a single sample of a single channel. 24
+// bits samples are packed on 3 bytes.
+func (f OutputFormat) BytesPerSample() int {
+	switch f {
+	case Int16OutputFormat:
+		return 2
+	case Int24OutputFormat:
+		return 3
+	case Int32OutputFormat, Float32OutputFormat:
+		return 4
+	}
+
+	return 0
+}
+
+// encode writes value to dst, which must hold at least BytesPerSample bytes.
+// Integer formats clamp the value between -1.0 and 1.0, float32 keeps it as is.
+func (f OutputFormat) encode(value SampleType, dst []byte) {
+	if f == Float32OutputFormat {
+		binary.LittleEndian.PutUint32(dst, math.Float32bits(float32(value)))
+		return
+	}
+
+	if value > 1.0 {
+		value = 1.0
+	} else if value < -1.0 {
+		value = -1.0
+	}
+
+	switch f {
+	case Int16OutputFormat:
+		binary.LittleEndian.PutUint16(dst, uint16(int16(value*math.MaxInt16)))
+	case Int24OutputFormat:
+		sample := int32(value * (1<<23 - 1))
+		dst[0] = byte(sample >> 0)
+		dst[1] = byte(sample >> 8)
+		dst[2] = byte(sample >> 16)
+	case Int32OutputFormat:
+		binary.LittleEndian.PutUint32(dst, uint32(int32(value*math.MaxInt32)))
+	}
+}
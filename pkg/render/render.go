@@ -0,0 +1,211 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/sywesk/audiomix/pkg/audiograph"
+	"github.com/sywesk/audiomix/pkg/wav"
+)
+
+var (
+	ErrNoSamplingFrequency = fmt.Errorf("graph has no sampling frequency")
+	ErrUnsupportedFormat   = fmt.Errorf("unsupported format")
+	ErrInvalidStem         = fmt.Errorf("invalid stem")
+)
+
+var (
+	outputFormats = map[wav.Format]audiograph.OutputFormat{
+		wav.PCM16Format:   audiograph.Int16OutputFormat,
+		wav.PCM24Format:   audiograph.Int24OutputFormat,
+		wav.PCM32Format:   audiograph.Int32OutputFormat,
+		wav.Float32Format: audiograph.Float32OutputFormat,
+	}
+)
+
+// DefaultFormat returns the WAV format matching the output format of the graph.
+func DefaultFormat(graph *audiograph.AudioGraph) wav.Format {
+	outputFormat := graph.OutputFormat()
+
+	for format, candidate := range outputFormats {
+		if candidate == outputFormat {
+			return format
+		}
+	}
+
+	return wav.PCM16Format
+}
+
+// RenderFile renders the graph into a new WAV file at path. See Render.
+func RenderFile(graph *audiograph.AudioGraph, duration time.Duration, format wav.Format, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	err = Render(graph, duration, format, file)
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+// Render pulls duration worth of frames out of the graph, at its sampling
+// frequency, and writes them as a WAV file to w. There is no real-time pacing:
+// the graph is pulled as fast as it computes. The output format of the graph
+// is changed to match the requested WAV format, and the file gets as many
+// channels as the graph.
+func Render(graph *audiograph.AudioGraph, duration time.Duration, format wav.Format, w io.WriteSeeker) error {
+	err := setFormat(graph, format)
+	if err != nil {
+		return err
+	}
+
+	writer, err := wav.NewWriter(w, format, uint16(graph.Channels()), graph.SamplingFrequency())
+	if err != nil {
+		return fmt.Errorf("failed to create wav writer: %w", err)
+	}
+
+	err = pull(graph, duration, func(frames []byte) error {
+		_, err := writer.Write(frames)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Close()
+}
+
+// Stem is a group of channels of the graph rendered to their own file.
+type Stem struct {
+	// Channels lists the channels of the graph written to the file, in order.
+	// Channels are numbered from 0.
+	Channels []int
+	Path     string
+}
+
+// RenderStemFiles renders the graph like Render does, but splits its channels
+// into several WAV files, one per stem.
+func RenderStemFiles(graph *audiograph.AudioGraph, duration time.Duration, format wav.Format, stems []Stem) error {
+	err := setFormat(graph, format)
+	if err != nil {
+		return err
+	}
+
+	channels := graph.Channels()
+	sampleSize := format.BytesPerSample()
+	frameSize := graph.FrameSize()
+
+	writers := make([]*wav.Writer, len(stems))
+	for i, stem := range stems {
+		if len(stem.Channels) == 0 {
+			return fmt.Errorf("stem %s has no channel: %w", stem.Path, ErrInvalidStem)
+		}
+
+		for _, channel := range stem.Channels {
+			if channel < 0 || channel >= channels {
+				return fmt.Errorf("stem %s uses channel index %d, the graph has %d channels: %w", stem.Path, channel, channels, ErrInvalidStem)
+			}
+		}
+
+		file, err := os.Create(stem.Path)
+		if err != nil {
+			return fmt.Errorf("failed to create file %s: %w", stem.Path, err)
+		}
+		defer file.Close()
+
+		writers[i], err = wav.NewWriter(file, format, uint16(len(stem.Channels)), graph.SamplingFrequency())
+		if err != nil {
+			return fmt.Errorf("failed to create wav writer: %w", err)
+		}
+	}
+
+	var stemBuffer []byte
+
+	err = pull(graph, duration, func(frames []byte) error {
+		frameCount := len(frames) / frameSize
+
+		for i, stem := range stems {
+			// Gather the channels of the stem, frame by frame
+			stemBuffer = stemBuffer[:0]
+			for frame := 0; frame < frameCount; frame++ {
+				for _, channel := range stem.Channels {
+					offset := frame*frameSize + channel*sampleSize
+					stemBuffer = append(stemBuffer, frames[offset:offset+sampleSize]...)
+				}
+			}
+
+			_, err := writers[i].Write(stemBuffer)
+			if err != nil {
+				return fmt.Errorf("failed to write to %s: %w", stem.Path, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, writer := range writers {
+		err := writer.Close()
+		if err != nil {
+			return fmt.Errorf("failed to finalize %s: %w", stems[i].Path, err)
+		}
+	}
+
+	return nil
+}
+
+func setFormat(graph *audiograph.AudioGraph, format wav.Format) error {
+	if graph.SamplingFrequency() == 0 {
+		return ErrNoSamplingFrequency
+	}
+
+	outputFormat, ok := outputFormats[format]
+	if !ok {
+		return ErrUnsupportedFormat
+	}
+
+	err := graph.SetOutputFormat(outputFormat)
+	if err != nil {
+		return fmt.Errorf("failed to set output format: %w", err)
+	}
+
+	return nil
+}
+
+// pull reads duration worth of frames out of the graph, handing them to write
+// chunk by chunk.
+func pull(graph *audiograph.AudioGraph, duration time.Duration, write func(frames []byte) error) error {
+	frameSize := graph.FrameSize()
+	remainingFrames := int(math.Round(duration.Seconds() * float64(graph.SamplingFrequency())))
+	buffer := make([]byte, 1024*frameSize)
+
+	for remainingFrames > 0 {
+		chunk := buffer
+		if remainingFrames*frameSize < len(chunk) {
+			chunk = chunk[:remainingFrames*frameSize]
+		}
+
+		n, err := graph.Read(chunk)
+		if err != nil {
+			return fmt.Errorf("failed to read from graph: %w", err)
+		}
+
+		err = write(chunk[:n])
+		if err != nil {
+			return fmt.Errorf("failed to write samples: %w", err)
+		}
+
+		remainingFrames -= n / frameSize
+	}
+
+	return nil
+}
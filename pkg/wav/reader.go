@@ -0,0 +1,236 @@
+package wav
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+var (
+	ErrInvalidFile       = fmt.Errorf("invalid wav file")
+	ErrUnsupportedFormat = fmt.Errorf("unsupported wav format")
+)
+
+const (
+	extensibleAudioFormat uint16 = 0xFFFE
+)
+
+// Audio is the decoded content of a WAV file. Channels holds one slice of
+// samples per channel, scaled between -1.0 and 1.0 whatever the encoding of
+// the file.
+type Audio struct {
+	SampleRate uint32
+	Channels   [][]float64
+}
+
+// Frames returns the number of samples in each channel.
+func (a *Audio) Frames() int {
+	if len(a.Channels) == 0 {
+		return 0
+	}
+
+	return len(a.Channels[0])
+}
+
+func ReadFile(path string) (*Audio, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	audio, err := Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode file %s: %w", path, err)
+	}
+
+	return audio, nil
+}
+
+// Decode reads a RIFF/WAVE stream holding 8, 16, 24 or 32 bits PCM samples,
+// or 32 or 64 bits float samples. Chunks other than fmt and data are skipped.
+func Decode(r io.Reader) (*Audio, error) {
+	riffHeader := make([]byte, 12)
+	_, err := io.ReadFull(r, riffHeader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read riff header: %w", err)
+	}
+
+	if string(riffHeader[0:4]) != "RIFF" || string(riffHeader[8:12]) != "WAVE" {
+		return nil, ErrInvalidFile
+	}
+
+	var audioFormat, channels, bitsPerSample uint16
+	var sampleRate uint32
+	fmtFound := false
+
+	for {
+		chunkHeader := make([]byte, 8)
+		_, err := io.ReadFull(r, chunkHeader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chunk header: %w", err)
+		}
+
+		chunkID := string(chunkHeader[0:4])
+		chunkSize := binary.LittleEndian.Uint32(chunkHeader[4:8])
+
+		// Chunks are padded to an even size
+		paddedSize := int64(chunkSize) + int64(chunkSize%2)
+
+		switch chunkID {
+		case "fmt ":
+			if chunkSize < 16 {
+				return nil, fmt.Errorf("fmt chunk too small: %w", ErrInvalidFile)
+			}
+
+			chunk, err := readChunk(r, paddedSize)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read fmt chunk: %w", err)
+			}
+
+			audioFormat = binary.LittleEndian.Uint16(chunk[0:2])
+			channels = binary.LittleEndian.Uint16(chunk[2:4])
+			sampleRate = binary.LittleEndian.Uint32(chunk[4:8])
+			bitsPerSample = binary.LittleEndian.Uint16(chunk[14:16])
+
+			// The actual format of extensible files is held by the first two
+			// bytes of the sub format GUID
+			if audioFormat == extensibleAudioFormat {
+				if chunkSize < 26 {
+					return nil, fmt.Errorf("extensible fmt chunk too small: %w", ErrInvalidFile)
+				}
+
+				audioFormat = binary.LittleEndian.Uint16(chunk[24:26])
+			}
+
+			fmtFound = true
+
+		case "data":
+			if !fmtFound {
+				return nil, fmt.Errorf("data chunk before fmt chunk: %w", ErrInvalidFile)
+			}
+
+			data, err := readChunk(r, int64(chunkSize))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read data chunk: %w", err)
+			}
+
+			return decodeSamples(data, audioFormat, channels, bitsPerSample, sampleRate)
+
+		default:
+			_, err := io.CopyN(io.Discard, r, paddedSize)
+			if err != nil {
+				return nil, fmt.Errorf("failed to skip chunk '%s': %w", chunkID, err)
+			}
+		}
+	}
+}
+
+// readChunk reads the size bytes of a chunk. The size comes from the chunk
+// header and is checked against what is left in the stream before allocating,
+// so that a corrupt header cannot trigger a huge allocation.
+func readChunk(r io.Reader, size int64) ([]byte, error) {
+	if seeker, ok := r.(io.Seeker); ok {
+		current, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+
+		end, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = seeker.Seek(current, io.SeekStart)
+		if err != nil {
+			return nil, err
+		}
+
+		if size > end-current {
+			return nil, fmt.Errorf("chunk of %d bytes with %d bytes left: %w", size, end-current, io.ErrUnexpectedEOF)
+		}
+
+		chunk := make([]byte, size)
+		_, err = io.ReadFull(r, chunk)
+		return chunk, err
+	}
+
+	// The buffer only grows with the bytes actually read
+	chunk, err := io.ReadAll(io.LimitReader(r, size))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(chunk)) < size {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	return chunk, nil
+}
+
+func decodeSamples(data []byte, audioFormat uint16, channels uint16, bitsPerSample uint16, sampleRate uint32) (*Audio, error) {
+	if channels == 0 {
+		return nil, fmt.Errorf("no channel: %w", ErrInvalidFile)
+	}
+
+	decodeSample, err := sampleDecoder(audioFormat, bitsPerSample)
+	if err != nil {
+		return nil, err
+	}
+
+	bytesPerSample := int(bitsPerSample / 8)
+	frames := len(data) / (bytesPerSample * int(channels))
+
+	audio := &Audio{
+		SampleRate: sampleRate,
+		Channels:   make([][]float64, channels),
+	}
+
+	for channel := range audio.Channels {
+		audio.Channels[channel] = make([]float64, frames)
+	}
+
+	for frame := 0; frame < frames; frame++ {
+		for channel := 0; channel < int(channels); channel++ {
+			offset := (frame*int(channels) + channel) * bytesPerSample
+			audio.Channels[channel][frame] = decodeSample(data[offset : offset+bytesPerSample])
+		}
+	}
+
+	return audio, nil
+}
+
+func sampleDecoder(audioFormat uint16, bitsPerSample uint16) (func([]byte) float64, error) {
+	switch {
+	case audioFormat == pcmAudioFormat && bitsPerSample == 8:
+		// 8 bits samples are the only unsigned ones
+		return func(b []byte) float64 {
+			return (float64(b[0]) - 128) / 128
+		}, nil
+	case audioFormat == pcmAudioFormat && bitsPerSample == 16:
+		return func(b []byte) float64 {
+			return float64(int16(binary.LittleEndian.Uint16(b))) / (1 << 15)
+		}, nil
+	case audioFormat == pcmAudioFormat && bitsPerSample == 24:
+		return func(b []byte) float64 {
+			value := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+			return float64(value) / (1 << 23)
+		}, nil
+	case audioFormat == pcmAudioFormat && bitsPerSample == 32:
+		return func(b []byte) float64 {
+			return float64(int32(binary.LittleEndian.Uint32(b))) / (1 << 31)
+		}, nil
+	case audioFormat == floatAudioFormat && bitsPerSample == 32:
+		return func(b []byte) float64 {
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		}, nil
+	case audioFormat == floatAudioFormat && bitsPerSample == 64:
+		return func(b []byte) float64 {
+			return math.Float64frombits(binary.LittleEndian.Uint64(b))
+		}, nil
+	}
+
+	return nil, fmt.Errorf("format %d with %d bits per sample: %w", audioFormat, bitsPerSample, ErrUnsupportedFormat)
+}
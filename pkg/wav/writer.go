@@ -0,0 +1,203 @@
+package wav
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+var (
+	ErrUnknownFormat = fmt.Errorf("unknown format")
+)
+
+type Format int
+
+const (
+	PCM16Format   Format = 1
+	Float32Format Format = 2
+	PCM24Format   Format = 3
+	PCM32Format   Format = 4
+)
+
+var (
+	formatNames = map[string]Format{
+		"pcm16":   PCM16Format,
+		"pcm24":   PCM24Format,
+		"pcm32":   PCM32Format,
+		"float32": Float32Format,
+	}
+)
+
+const (
+	pcmAudioFormat   uint16 = 1
+	floatAudioFormat uint16 = 3
+
+	// fmtChunkSize and extensibleFmtChunkSize are the sizes of the plain and
+	// extensible fmt chunks, their own header excluded.
+	fmtChunkSize           = 16
+	extensibleFmtChunkSize = 40
+)
+
+var (
+	// subFormatGUIDSuffix follows the audio format in the sub format GUID of
+	// extensible fmt chunks.
+	subFormatGUIDSuffix = []byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71}
+)
+
+func ParseFormat(name string) (Format, error) {
+	format, ok := formatNames[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("format '%s': %w", name, ErrUnknownFormat)
+	}
+
+	return format, nil
+}
+
+// BytesPerSample returns the size of a single sample of a single channel.
+func (f Format) BytesPerSample() int {
+	switch f {
+	case PCM16Format:
+		return 2
+	case PCM24Format:
+		return 3
+	case PCM32Format, Float32Format:
+		return 4
+	}
+
+	return 0
+}
+
+func (f Format) audioFormat() uint16 {
+	if f == Float32Format {
+		return floatAudioFormat
+	}
+
+	return pcmAudioFormat
+}
+
+// Writer writes a RIFF/WAVE file. Samples are written as raw little endian
+// interleaved bytes, matching the format given to NewWriter. Sizes in the
+// header are only known once every sample is written: Close must be called to
+// patch them. Files with more than 2 channels or integer samples of more than
+// 16 bits get a WAVE_FORMAT_EXTENSIBLE header, as players expect.
+type Writer struct {
+	writer     io.WriteSeeker
+	format     Format
+	channels   uint16
+	sampleRate uint32
+	dataSize   uint32
+}
+
+func NewWriter(w io.WriteSeeker, format Format, channels uint16, sampleRate uint32) (*Writer, error) {
+	if format.BytesPerSample() == 0 {
+		return nil, ErrUnknownFormat
+	}
+
+	writer := &Writer{
+		writer:     w,
+		format:     format,
+		channels:   channels,
+		sampleRate: sampleRate,
+	}
+
+	err := writer.writeHeader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+
+	return writer, nil
+}
+
+func (w *Writer) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.dataSize += uint32(n)
+
+	return n, err
+}
+
+// Close patches the header with the final sizes. It does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	_, err := w.writer.Seek(0, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("failed to seek to header: %w", err)
+	}
+
+	err = w.writeHeader()
+	if err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	_, err = w.writer.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to seek to end: %w", err)
+	}
+
+	return nil
+}
+
+// extensible tells whether the header needs a WAVE_FORMAT_EXTENSIBLE fmt
+// chunk.
+func (w *Writer) extensible() bool {
+	return w.channels > 2 || (w.format.audioFormat() == pcmAudioFormat && w.format.BytesPerSample() > 2)
+}
+
+// channelMask assigns the first speaker positions to the channels, in order.
+// Positions are left unassigned when there are more channels than speakers.
+func (w *Writer) channelMask() uint32 {
+	if w.channels > 18 {
+		return 0
+	}
+
+	return 1<<w.channels - 1
+}
+
+func (w *Writer) writeHeader() error {
+	bytesPerSample := uint16(w.format.BytesPerSample())
+	blockAlign := w.channels * bytesPerSample
+
+	fmtSize := uint32(fmtChunkSize)
+	audioFormat := w.format.audioFormat()
+	if w.extensible() {
+		fmtSize = extensibleFmtChunkSize
+		audioFormat = extensibleAudioFormat
+	}
+
+	// RIFF header, fmt chunk and data chunk header
+	headerSize := 12 + 8 + fmtSize + 8
+	header := make([]byte, headerSize)
+
+	// RIFF chunk
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], headerSize-8+w.dataSize)
+	copy(header[8:12], "WAVE")
+
+	// fmt chunk
+	copy(header[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(header[16:20], fmtSize)
+	binary.LittleEndian.PutUint16(header[20:22], audioFormat)
+	binary.LittleEndian.PutUint16(header[22:24], w.channels)
+	binary.LittleEndian.PutUint32(header[24:28], w.sampleRate)
+	binary.LittleEndian.PutUint32(header[28:32], w.sampleRate*uint32(blockAlign))
+	binary.LittleEndian.PutUint16(header[32:34], blockAlign)
+	binary.LittleEndian.PutUint16(header[34:36], bytesPerSample*8)
+
+	if w.extensible() {
+		// Size of the extension, valid bits per sample, channel mask and sub
+		// format, which holds the actual audio format
+		binary.LittleEndian.PutUint16(header[36:38], extensibleFmtChunkSize-fmtChunkSize-2)
+		binary.LittleEndian.PutUint16(header[38:40], bytesPerSample*8)
+		binary.LittleEndian.PutUint32(header[40:44], w.channelMask())
+		binary.LittleEndian.PutUint16(header[44:46], w.format.audioFormat())
+		copy(header[46:60], subFormatGUIDSuffix)
+	}
+
+	// data chunk
+	data := headerSize - 8
+	copy(header[data:data+4], "data")
+	binary.LittleEndian.PutUint32(header[data+4:data+8], w.dataSize)
+
+	_, err := w.writer.Write(header)
+	return err
+}